@@ -12,20 +12,36 @@ import (
 
 func NewApplyCmd() *cobra.Command {
 	auto := false
+	transactional := false
+	verify := true
+	parallelism := 1
+	filter := &filterFlags{}
+	output := pkg.OutputFormatText
 
 	applyCmd := &cobra.Command{
 		Use:   "apply",
-		Short: "Apply the plan, grept apply [-a] [path to config files]",
-		RunE:  applyFunc(&auto),
+		Short: "Apply the plan, grept apply [-a] [--transactional] [--parallelism n] [--verify=false] [--target selector] [--exclude selector] [-o text|json|sarif|junit] [path to config files | path to saved plan file]",
+		RunE:  applyFunc(&auto, &transactional, &verify, &parallelism, &output, filter),
 	}
 
 	applyCmd.Flags().BoolVarP(&auto, "auto", "a", false, "Apply fixes without confirmation")
+	applyCmd.Flags().BoolVar(&transactional, "transactional", false, "Stop on the first failed fix and roll back all changes made by applied fixes")
+	applyCmd.Flags().IntVar(&parallelism, "parallelism", 1, "Max number of fixes applied at the same time, fixes that touch the same paths or depend on each other are applied one by one.")
+	applyCmd.Flags().BoolVar(&verify, "verify", true, "Re-check all rules after apply, fail when fixes didn't fix their rules or any rule is newly failing. Data sources are not read again")
+	applyCmd.Flags().StringVarP(&output, "output", "o", pkg.OutputFormatText, "Output format of the plan, can be text, json, sarif or junit")
+	filter.register(applyCmd)
 
 	return applyCmd
 }
 
-func applyFunc(auto *bool) func(*cobra.Command, []string) error {
+func applyFunc(auto, transactional, verify *bool, parallelism *int, output *string, filter *filterFlags) func(*cobra.Command, []string) error {
 	return func(c *cobra.Command, args []string) error {
+		if err := validateOutputFormat(*output); err != nil {
+			return err
+		}
+		if *parallelism < 1 {
+			return fmt.Errorf("--parallelism must be at least 1, got %d", *parallelism)
+		}
 		varFlags, err := varFlags(os.Args)
 		if err != nil {
 			return err
@@ -36,6 +52,15 @@ func applyFunc(auto *bool) func(*cobra.Command, []string) error {
 		} else {
 			cfgDir = args[0]
 		}
+		if isPlanFile(cfgDir) {
+			plan, err := pkg.LoadPlan(cfgDir)
+			if err != nil {
+				return fmt.Errorf("error loading plan %s: %+v", cfgDir, err)
+			}
+			// a saved plan has no config to re-run rules with, so there's no verification after apply
+			_, err = applyPlan(plan, *auto, *transactional, *parallelism, *output)
+			return err
+		}
 		configPath, cleaner, err := getConfigFolder(cfgDir, c.Context())
 		if cleaner != nil {
 			defer cleaner()
@@ -48,7 +73,7 @@ func applyFunc(auto *bool) func(*cobra.Command, []string) error {
 		if err != nil {
 			return fmt.Errorf("error getting os wd: %+v", err)
 		}
-		config, err := pkg.BuildGreptConfig(pwd, configPath, c.Context(), varFlags)
+		config, err := pkg.BuildFilteredGreptConfig(pwd, configPath, c.Context(), varFlags, filter.blockFilter())
 		if err != nil {
 			return fmt.Errorf("error parsing config: %s", err.Error())
 		}
@@ -57,31 +82,59 @@ func applyFunc(auto *bool) func(*cobra.Command, []string) error {
 		if err != nil {
 			return fmt.Errorf("error generating plan: %s", err.Error())
 		}
+		applied, err := applyPlan(plan, *auto, *transactional, *parallelism, *output)
+		if err != nil || !applied || !*verify {
+			return err
+		}
 
-		if len(plan.FailedRules) == 0 {
-			fmt.Println("All rule checks successful, nothing to do.")
-			return nil
+		verification, err := plan.Verify()
+		if err != nil {
+			return fmt.Errorf("error verifying plan: %s", err.Error())
 		}
+		messages := messageWriter(*output)
+		_, _ = fmt.Fprintf(messages, "Verification after apply:\n%s", verification.String())
+		if verification.Failed() {
+			c.SilenceUsage = true
+			return fmt.Errorf("verification failed, %d rule(s) not fixed by their fixes, %d rule(s) newly failing", len(verification.IneffectiveFixes), len(verification.NewlyFailing))
+		}
+		return nil
+	}
+}
 
-		fmt.Println(plan.String())
+// applyPlan prints the plan and applies it after confirmation, it returns false if there's nothing to apply or the plan is declined.
+func applyPlan(plan *pkg.GreptPlan, auto, transactional bool, parallelism int, output string) (bool, error) {
+	if err := printPlan(plan, output); err != nil {
+		return false, err
+	}
+	if len(plan.FailedRules) == 0 {
+		return false, nil
+	}
 
-		if !*auto {
-			reader := bufio.NewReader(os.Stdin)
-			fmt.Print("Do you want to apply this plan? Only `yes` would be accepted. (yes/no): ")
-			text, _ := reader.ReadString('\n')
-			text = strings.ToLower(strings.TrimSpace(text))
+	messages := messageWriter(output)
+	if !auto {
+		reader := bufio.NewReader(os.Stdin)
+		_, _ = fmt.Fprint(messages, "Do you want to apply this plan? Only `yes` would be accepted. (yes/no): ")
+		text, _ := reader.ReadString('\n')
+		text = strings.ToLower(strings.TrimSpace(text))
 
-			if text != "yes" {
-				return nil
-			}
+		if text != "yes" {
+			return false, nil
 		}
-		err = plan.Apply()
-		if err != nil {
-			return fmt.Errorf("error applying plan: %s", err.Error())
-		}
-		fmt.Println("Plan applied successfully.")
-		return nil
 	}
+	plan.Transactional = transactional
+	plan.Parallelism = parallelism
+	err := plan.Apply()
+	if err != nil {
+		return false, fmt.Errorf("error applying plan: %s", err.Error())
+	}
+	_, _ = fmt.Fprintln(messages, "Plan applied successfully.")
+	return true, nil
+}
+
+// isPlanFile returns true if the path is a regular file, which should be a plan saved by `grept plan --out`.
+func isPlanFile(path string) bool {
+	info, err := pkg.FsFactory().Stat(path)
+	return err == nil && info.Mode().IsRegular()
 }
 
 func init() {
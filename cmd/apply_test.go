@@ -70,8 +70,90 @@ func TestApplyFunc(t *testing.T) {
 	output := string(out)
 
 	assert.Contains(t, output, "Plan applied successfully.")
+	assert.Contains(t, output, "rule.file_hash.test resolved")
 
 	// ExecuteDuringPlan if the fix was applied
 	fixedContent, _ := afero.ReadFile(mockFs, "test.txt")
 	assert.Equal(t, expectedContent, string(fixedContent))
 }
+
+func TestApplyFunc_VerifyShouldFailWhenFixDidNotFixRule(t *testing.T) {
+	configContent := `
+		rule "must_be_true" "test" {
+			condition = false
+		}
+
+		fix "local_file" "test" {
+			paths = ["test.txt"]
+			content = "hello"
+			rule_ids = [rule.must_be_true.test.id]
+		}
+	`
+	mockFs := afero.NewMemMapFs()
+	stub := gostub.Stub(&pkg.FsFactory, func() afero.Fs {
+		return mockFs
+	})
+	defer stub.Reset()
+	_ = afero.WriteFile(mockFs, "./test_config.grept.hcl", []byte(configContent), 0644)
+	r, w, _ := os.Pipe()
+	stub.Stub(&os.Stdout, w)
+
+	cmd := NewApplyCmd()
+	cmd.SetContext(context.TODO())
+	_ = cmd.Flags().Set("auto", "true")
+	err := cmd.RunE(cmd, []string{"."})
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "verification failed")
+	assert.Contains(t, string(out), "rule.must_be_true.test still failing, applied fix.local_file.test did not fix it")
+
+	_ = cmd.Flags().Set("verify", "false")
+	err = cmd.RunE(cmd, []string{"."})
+	require.NoError(t, err)
+}
+
+func TestApplyFunc_SavedPlan(t *testing.T) {
+	configContent := `
+		rule "file_exist" "test" {
+			glob = "/test.txt"
+		}
+
+		fix "local_file" "test" {
+			paths = ["/test.txt"]
+			content = "hello"
+			rule_ids = [rule.file_exist.test.id]
+		}
+	`
+	mockFs := afero.NewMemMapFs()
+	stub := gostub.Stub(&pkg.FsFactory, func() afero.Fs {
+		return mockFs
+	})
+	defer stub.Reset()
+	_ = afero.WriteFile(mockFs, "/cfg/test_config.grept.hcl", []byte(configContent), 0644)
+	r, w, _ := os.Pipe()
+	stub.Stub(&os.Stdout, w)
+
+	planCmd := NewPlanCmd()
+	planCmd.SetContext(context.TODO())
+	_ = planCmd.Flags().Set("out", "/plan.json")
+	require.NoError(t, planCmd.RunE(planCmd, []string{"/cfg"}))
+
+	applyCmd := NewApplyCmd()
+	applyCmd.SetContext(context.TODO())
+	_ = applyCmd.Flags().Set("auto", "true")
+	require.NoError(t, applyCmd.RunE(applyCmd, []string{"/plan.json"}))
+	_ = w.Close()
+	out, _ := io.ReadAll(r)
+	assert.Contains(t, string(out), "Plan saved to /plan.json")
+	assert.Contains(t, string(out), "Plan applied successfully.")
+	content, err := afero.ReadFile(mockFs, "/test.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	// the tree has drifted since the plan was saved
+	err = applyCmd.RunE(applyCmd, []string{"/plan.json"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "drifted")
+}
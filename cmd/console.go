@@ -14,16 +14,18 @@ import (
 )
 
 func NewConsoleCmd() *cobra.Command {
+	filter := &filterFlags{}
 	replCmd := &cobra.Command{
 		Use:   "console",
-		Short: "Start REPL mode, grept console [path to config files]",
-		RunE:  replFunc(),
+		Short: "Start REPL mode, grept console [--target selector] [--exclude selector] [path to config files]",
+		RunE:  replFunc(filter),
 	}
+	filter.register(replCmd)
 
 	return replCmd
 }
 
-func replFunc() func(*cobra.Command, []string) error {
+func replFunc(filter *filterFlags) func(*cobra.Command, []string) error {
 	return func(c *cobra.Command, args []string) error {
 		varFlags, err := varFlags(os.Args)
 		if err != nil {
@@ -47,7 +49,7 @@ func replFunc() func(*cobra.Command, []string) error {
 		if err != nil {
 			return fmt.Errorf("error getting os wd: %+v", err)
 		}
-		config, err := pkg.BuildGreptConfig(pwd, configPath, c.Context(), varFlags)
+		config, err := pkg.BuildFilteredGreptConfig(pwd, configPath, c.Context(), varFlags, filter.blockFilter())
 		if err != nil {
 			return fmt.Errorf("error parsing config: %+v", err)
 		}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/Azure/grept/pkg"
+	"github.com/spf13/cobra"
+)
+
+// filterFlags are `--target` and `--exclude` flags shared by commands that evaluate the config.
+type filterFlags struct {
+	targets  []string
+	excludes []string
+}
+
+func (f *filterFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&f.targets, "target", nil, "Only evaluate the selected rules, fixes linked to them and the blocks they depend on. Could be block address with wildcards like rule.file_hash.*, fix.local_file.license, tag:<tag> or severity:<severity>. Use this option more than once to set more than one selector")
+	cmd.Flags().StringSliceVar(&f.excludes, "exclude", nil, "Skip the selected rules and fixes, accepts the same selectors as --target. Use this option more than once to set more than one selector")
+}
+
+func (f *filterFlags) blockFilter() *pkg.BlockFilter {
+	return &pkg.BlockFilter{
+		Targets:  f.targets,
+		Excludes: f.excludes,
+	}
+}
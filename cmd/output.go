@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Azure/grept/pkg"
+	"github.com/ahmetb/go-linq/v3"
+)
+
+func validateOutputFormat(format string) error {
+	if linq.From(pkg.OutputFormats).Contains(format) {
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %s, valid formats are: %s", format, strings.Join(pkg.OutputFormats, ", "))
+}
+
+// messageWriter returns where human-readable messages go, structured output keeps stdout for the report only.
+func messageWriter(format string) io.Writer {
+	if format == pkg.OutputFormatText {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+func printPlan(plan *pkg.GreptPlan, format string) error {
+	if format == pkg.OutputFormatText && len(plan.FailedRules) == 0 && len(plan.SuppressedRules) == 0 {
+		fmt.Println("All rule checks successful, nothing to do.")
+		return nil
+	}
+	out, err := plan.Render(format)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
@@ -7,17 +7,50 @@ import (
 	"os"
 )
 
+// exitCodeFailure is returned by `grept plan --detailed-exitcode` when blocking rule check failures are found, errors exit with 1.
+const exitCodeFailure = 2
+
+type planFlags struct {
+	output           string
+	detailedExitCode bool
+	failOn           []string
+	diff             bool
+	out              string
+	writeBaseline    string
+	baseline         string
+	graph            bool
+	filter           filterFlags
+}
+
 func NewPlanCmd() *cobra.Command {
+	flags := &planFlags{}
+
 	cmd := &cobra.Command{
 		Use:   "plan",
-		Short: "Generates a plan based on the specified configuration, grept plan [path to config files]",
-		RunE:  planFunc(),
+		Short: "Generates a plan based on the specified configuration, grept plan [-o text|json|sarif|junit] [--detailed-exitcode] [--fail-on selector] [--diff] [--graph] [--out plan file] [--write-baseline baseline file] [--baseline baseline file] [--target selector] [--exclude selector] [path to config files]",
+		RunE:  planFunc(flags),
 	}
+
+	cmd.Flags().StringVarP(&flags.output, "output", "o", pkg.OutputFormatText, "Output format of the plan, can be text, json, sarif or junit")
+	cmd.Flags().BoolVar(&flags.detailedExitCode, "detailed-exitcode", false, "Return detailed exit codes: 0 - no blocking rule check failure, 1 - error, 2 - blocking rule check failures found")
+	cmd.Flags().StringSliceVar(&flags.failOn, "fail-on", nil, "Rules that count as blocking, could be rule address with wildcards like rule.file_hash.*, tag:<tag> or severity:<severity>. Use this option more than once to set more than one selector. Implies --detailed-exitcode, failed rules with error severity are blocking when omitted")
+	cmd.Flags().BoolVar(&flags.diff, "diff", false, "Print unified diffs of file changes that fixes would make, the fixes are applied to an in-memory copy of files")
+	cmd.Flags().BoolVar(&flags.graph, "graph", false, "Print fixes in the plan and the order they would be applied in as a DOT graph instead of the plan, other messages are written to stderr")
+	cmd.Flags().StringVar(&flags.out, "out", "", "Save the plan to a file, so it could be applied later by grept apply <plan file> without evaluating the config again")
+	cmd.Flags().StringVar(&flags.writeBaseline, "write-baseline", "", "Record all current rule check failures to a baseline file")
+	cmd.Flags().StringVar(&flags.baseline, "baseline", "", "Only report rule check failures that are not in the baseline file written by --write-baseline")
+	flags.filter.register(cmd)
 	return cmd
 }
 
-func planFunc() func(*cobra.Command, []string) error {
+func planFunc(flags *planFlags) func(*cobra.Command, []string) error {
 	return func(c *cobra.Command, args []string) error {
+		if err := validateOutputFormat(flags.output); err != nil {
+			return err
+		}
+		if flags.graph && flags.output != pkg.OutputFormatText {
+			return fmt.Errorf("--graph prints a DOT graph instead of the plan, it can't be used with --output %s", flags.output)
+		}
 		varFlags, err := varFlags(os.Args)
 		if err != nil {
 			return err
@@ -40,7 +73,7 @@ func planFunc() func(*cobra.Command, []string) error {
 		if err != nil {
 			return fmt.Errorf("error getting os wd: %+v", err)
 		}
-		config, err := pkg.BuildGreptConfig(pwd, configPath, c.Context(), varFlags)
+		config, err := pkg.BuildFilteredGreptConfig(pwd, configPath, c.Context(), varFlags, flags.filter.blockFilter())
 		if err != nil {
 			return fmt.Errorf("error parsing config: %+v", err)
 		}
@@ -49,13 +82,63 @@ func planFunc() func(*cobra.Command, []string) error {
 		if err != nil {
 			return fmt.Errorf("error generating plan: %s", err.Error())
 		}
+		messages := messageWriter(flags.output)
+		if flags.graph {
+			// stdout is for the graph only, so it could be piped to `dot`
+			messages = os.Stderr
+		}
+		if flags.writeBaseline != "" {
+			if err = plan.WriteBaseline(flags.writeBaseline); err != nil {
+				return fmt.Errorf("error writing baseline to %s: %+v", flags.writeBaseline, err)
+			}
+			_, _ = fmt.Fprintf(messages, "%d rule check failure(s) recorded in baseline %s.\n", len(plan.FailedRules), flags.writeBaseline)
+		}
+		if flags.baseline != "" {
+			baseline, err := pkg.LoadBaseline(flags.baseline)
+			if err != nil {
+				return fmt.Errorf("error loading baseline %s: %+v", flags.baseline, err)
+			}
+			plan.ExcludeBaseline(baseline)
+			_, _ = fmt.Fprintf(messages, "%d known rule check failure(s) in baseline %s are not reported.\n", len(plan.BaselinedRules), flags.baseline)
+		}
 
-		if len(plan.FailedRules) == 0 {
-			fmt.Println("All rule checks successful, nothing to do.")
+		if flags.graph {
+			graph, err := plan.Graph()
+			if err != nil {
+				return fmt.Errorf("error generating graph: %+v", err)
+			}
+			fmt.Print(graph)
+		} else if err = printPlan(plan, flags.output); err != nil {
+			return err
+		}
+		if flags.out != "" {
+			if err = plan.Save(flags.out); err != nil {
+				return fmt.Errorf("error saving plan to %s: %+v", flags.out, err)
+			}
+			_, _ = fmt.Fprintf(messages, "Plan saved to %s, apply it with `grept apply %s`.\n", flags.out, flags.out)
+		}
+		if flags.diff && len(plan.Fixes) > 0 {
+			diff, err := plan.Diff()
+			if err != nil {
+				return fmt.Errorf("error generating diff: %+v", err)
+			}
+			_, _ = fmt.Fprint(messages, diff)
+		}
+		if !flags.detailedExitCode && len(flags.failOn) == 0 {
+			return nil
+		}
+		blockingRules, err := plan.BlockingRules(flags.failOn)
+		if err != nil {
+			return err
+		}
+		if len(blockingRules) == 0 {
 			return nil
 		}
-		fmt.Println(plan.String())
-		return nil
+		c.SilenceUsage = true
+		return &exitCodeError{
+			code: exitCodeFailure,
+			err:  fmt.Errorf("%d blocking rule check failure(s) found", len(blockingRules)),
+		}
 	}
 }
 
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/Azure/grept/pkg"
 	"github.com/prashantv/gostub"
@@ -12,6 +13,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -121,3 +123,305 @@ func TestPlanFunc_WithFailedCheck(t *testing.T) {
 	assert.Contains(t, output, "fix.local_file.test would be apply:")
 	assert.Contains(t, output, `"content":"Mock server response"`)
 }
+
+func TestPlanFunc_JsonOutput(t *testing.T) {
+	configContent := `
+		rule "must_be_true" test {
+			condition = false
+			error_message = "expected failure"
+		}
+	`
+
+	mockFs := afero.NewMemMapFs()
+	stub := gostub.Stub(&pkg.FsFactory, func() afero.Fs {
+		return mockFs
+	})
+	defer stub.Reset()
+
+	_ = afero.WriteFile(mockFs, "/cfg/test_config.grept.hcl", []byte(configContent), 0644)
+
+	// Redirect Stdout
+	r, w, _ := os.Pipe()
+	stub.Stub(&os.Stdout, w)
+
+	cmd := NewPlanCmd()
+	cmd.SetContext(context.TODO())
+	_ = cmd.Flags().Set("output", "json")
+	err := cmd.RunE(cmd, []string{"/cfg"})
+	require.NoError(t, err)
+
+	err = w.Close()
+	require.NoError(t, err)
+	out, _ := io.ReadAll(r)
+
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(out, &report))
+	failedRules, ok := report["failed_rules"].([]any)
+	require.True(t, ok)
+	require.Len(t, failedRules, 1)
+	assert.Equal(t, "rule.must_be_true.test", failedRules[0].(map[string]any)["address"])
+}
+
+func TestPlanFunc_Diff(t *testing.T) {
+	configContent := `
+		rule "file_hash" "test" {
+			glob = "/test.txt"
+			hash = sha1("new content")
+		}
+
+		fix "local_file" "test" {
+			paths = ["/test.txt"]
+			content = "new content"
+			rule_ids = [rule.file_hash.test.id]
+		}
+	`
+
+	mockFs := afero.NewMemMapFs()
+	stub := gostub.Stub(&pkg.FsFactory, func() afero.Fs {
+		return mockFs
+	})
+	defer stub.Reset()
+
+	_ = afero.WriteFile(mockFs, "/test.txt", []byte("old content"), 0644)
+	_ = afero.WriteFile(mockFs, "/cfg/test_config.grept.hcl", []byte(configContent), 0644)
+
+	// Redirect Stdout
+	r, w, _ := os.Pipe()
+	stub.Stub(&os.Stdout, w)
+
+	cmd := NewPlanCmd()
+	cmd.SetContext(context.TODO())
+	_ = cmd.Flags().Set("diff", "true")
+	err := cmd.RunE(cmd, []string{"/cfg"})
+	require.NoError(t, err)
+
+	err = w.Close()
+	require.NoError(t, err)
+	out, _ := io.ReadAll(r)
+
+	assert.Contains(t, string(out), "--- a/test.txt\n+++ b/test.txt\n@@ -1 +1 @@\n-old content\n+new content\n")
+	content, _ := afero.ReadFile(mockFs, "/test.txt")
+	assert.Equal(t, "old content", string(content))
+}
+
+func TestPlanFunc_TargetAndExclude(t *testing.T) {
+	configContent := `
+		rule "must_be_true" license {
+			condition = false
+			tags = ["legal"]
+		}
+
+		rule "must_be_true" readme {
+			condition = false
+		}
+
+		rule "must_be_true" security {
+			condition = false
+			tags = ["legal"]
+		}
+	`
+
+	mockFs := afero.NewMemMapFs()
+	stub := gostub.Stub(&pkg.FsFactory, func() afero.Fs {
+		return mockFs
+	})
+	defer stub.Reset()
+
+	_ = afero.WriteFile(mockFs, "/cfg/test_config.grept.hcl", []byte(configContent), 0644)
+
+	// Redirect Stdout
+	r, w, _ := os.Pipe()
+	stub.Stub(&os.Stdout, w)
+
+	cmd := NewPlanCmd()
+	cmd.SetContext(context.TODO())
+	_ = cmd.Flags().Set("target", "tag:legal")
+	_ = cmd.Flags().Set("exclude", "rule.must_be_true.security")
+	err := cmd.RunE(cmd, []string{"/cfg"})
+	require.NoError(t, err)
+
+	err = w.Close()
+	require.NoError(t, err)
+	out, _ := io.ReadAll(r)
+
+	assert.Contains(t, string(out), "rule.must_be_true.license")
+	assert.NotContains(t, string(out), "rule.must_be_true.readme")
+	assert.NotContains(t, string(out), "rule.must_be_true.security")
+}
+
+func TestPlanFunc_InvalidOutputFormat(t *testing.T) {
+	cmd := NewPlanCmd()
+	cmd.SetContext(context.TODO())
+	_ = cmd.Flags().Set("output", "yaml")
+	err := cmd.RunE(cmd, []string{"/cfg"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported output format")
+}
+
+func TestPlanFunc_DetailedExitCode(t *testing.T) {
+	configContent := `
+		rule "must_be_true" blocking {
+			condition = false
+		}
+
+		rule "must_be_true" other {
+			condition = false
+		}
+
+		rule "must_be_true" pass {
+			condition = true
+		}
+	`
+	cases := []struct {
+		desc     string
+		flags    map[string]string
+		wantCode int
+	}{
+		{
+			desc:     "without_detailed_exitcode",
+			flags:    map[string]string{},
+			wantCode: 0,
+		},
+		{
+			desc:     "detailed_exitcode",
+			flags:    map[string]string{"detailed-exitcode": "true"},
+			wantCode: 2,
+		},
+		{
+			desc:     "fail_on_failed_rule",
+			flags:    map[string]string{"fail-on": "rule.must_be_true.blocking"},
+			wantCode: 2,
+		},
+		{
+			desc:     "fail_on_wildcard",
+			flags:    map[string]string{"fail-on": "rule.must_be_*.*"},
+			wantCode: 2,
+		},
+		{
+			desc:     "fail_on_passed_rule",
+			flags:    map[string]string{"fail-on": "rule.must_be_true.pass"},
+			wantCode: 0,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			mockFs := afero.NewMemMapFs()
+			stub := gostub.Stub(&pkg.FsFactory, func() afero.Fs {
+				return mockFs
+			})
+			defer stub.Reset()
+			_ = afero.WriteFile(mockFs, "/cfg/test_config.grept.hcl", []byte(configContent), 0644)
+			_, w, _ := os.Pipe()
+			stub.Stub(&os.Stdout, w)
+			defer func() {
+				_ = w.Close()
+			}()
+
+			cmd := NewPlanCmd()
+			cmd.SetContext(context.TODO())
+			for n, v := range c.flags {
+				require.NoError(t, cmd.Flags().Set(n, v))
+			}
+			err := cmd.RunE(cmd, []string{"/cfg"})
+			if c.wantCode == 0 {
+				require.NoError(t, err)
+				return
+			}
+			var ee *exitCodeError
+			require.ErrorAs(t, err, &ee)
+			assert.Equal(t, c.wantCode, ee.code)
+		})
+	}
+}
+
+func TestPlanFunc_Baseline(t *testing.T) {
+	legacyContent := `
+		rule "must_be_true" license {
+			condition = false
+		}
+	`
+	newContent := `
+		rule "must_be_true" readme {
+			condition = false
+		}
+	`
+
+	mockFs := afero.NewMemMapFs()
+	stub := gostub.Stub(&pkg.FsFactory, func() afero.Fs {
+		return mockFs
+	})
+	defer stub.Reset()
+
+	_ = afero.WriteFile(mockFs, "/cfg/legacy.grept.hcl", []byte(legacyContent), 0644)
+	_, w, _ := os.Pipe()
+	stub.Stub(&os.Stdout, w)
+	cmd := NewPlanCmd()
+	cmd.SetContext(context.TODO())
+	_ = cmd.Flags().Set("write-baseline", "/baseline.json")
+	require.NoError(t, cmd.RunE(cmd, []string{"/cfg"}))
+	require.NoError(t, w.Close())
+
+	_ = afero.WriteFile(mockFs, "/cfg/new.grept.hcl", []byte(newContent), 0644)
+	r, w, _ := os.Pipe()
+	stub.Stub(&os.Stdout, w)
+	cmd = NewPlanCmd()
+	cmd.SetContext(context.TODO())
+	_ = cmd.Flags().Set("baseline", "/baseline.json")
+	_ = cmd.Flags().Set("detailed-exitcode", "true")
+	err := cmd.RunE(cmd, []string{"/cfg"})
+	var ee *exitCodeError
+	require.ErrorAs(t, err, &ee)
+	assert.Contains(t, ee.Error(), "1 blocking rule check failure(s) found")
+	require.NoError(t, w.Close())
+	out, _ := io.ReadAll(r)
+
+	assert.Contains(t, string(out), "rule.must_be_true.readme")
+	assert.Contains(t, string(out), "1 known rule check failure(s) in baseline /baseline.json are not reported")
+	assert.NotContains(t, string(out), "rule.must_be_true.license check return failure")
+}
+
+func TestPlanFunc_Graph(t *testing.T) {
+	configContent := `
+		rule "must_be_true" sample {
+			condition = false
+		}
+
+		fix "local_file" a {
+			rule_ids = [rule.must_be_true.sample.id]
+			paths    = ["/a"]
+			content  = "a"
+		}
+
+		fix "rename_file" a {
+			rule_ids   = [rule.must_be_true.sample.id]
+			old_name   = "/a"
+			new_name   = "/b"
+			depends_on = [fix.local_file.a]
+		}
+	`
+
+	mockFs := afero.NewMemMapFs()
+	stub := gostub.Stub(&pkg.FsFactory, func() afero.Fs {
+		return mockFs
+	})
+	defer stub.Reset()
+
+	_ = afero.WriteFile(mockFs, "/cfg/test_config.grept.hcl", []byte(configContent), 0644)
+
+	r, w, _ := os.Pipe()
+	stub.Stub(&os.Stdout, w)
+
+	cmd := NewPlanCmd()
+	cmd.SetContext(context.TODO())
+	_ = cmd.Flags().Set("graph", "true")
+	err := cmd.RunE(cmd, []string{"/cfg"})
+	require.NoError(t, err)
+
+	err = w.Close()
+	require.NoError(t, err)
+	out, _ := io.ReadAll(r)
+
+	assert.True(t, strings.HasPrefix(string(out), "digraph {"))
+	assert.Contains(t, string(out), `"fix.local_file.a" -> "fix.rename_file.a"`)
+	assert.NotContains(t, string(out), "check return failure")
+}
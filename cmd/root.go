@@ -29,10 +29,28 @@ func Execute() {
 		if errors.As(err, &pe) {
 			os.Exit(pe.ExitCode())
 		}
+		var ee *exitCodeError
+		if errors.As(err, &ee) {
+			os.Exit(ee.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitCodeError is returned by commands that want to exit with a specific code.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
 var cf = &commonFlags{}
 
 type commonFlags struct {
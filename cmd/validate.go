@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/Azure/grept/pkg"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func NewValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check config files without running any rule or data source, grept validate [path to config files]",
+		RunE:  validateFunc,
+	}
+}
+
+func validateFunc(c *cobra.Command, args []string) error {
+	var cfgDir string
+	if len(args) == 0 {
+		cfgDir = "."
+	} else {
+		cfgDir = args[0]
+	}
+	configPath, cleaner, err := getConfigFolder(cfgDir, c.Context())
+	if cleaner != nil {
+		defer cleaner()
+	}
+	if err != nil {
+		return fmt.Errorf("error getting config %s: %+v", cfgDir, err)
+	}
+	pwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting os wd: %+v", err)
+	}
+	validation, err := pkg.ValidateConfig(pwd, configPath)
+	if err != nil {
+		return err
+	}
+	for _, warning := range validation.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	for _, validationErr := range validation.Errors {
+		fmt.Printf("Error: %s\n", validationErr.Error())
+	}
+	if !validation.Valid() {
+		c.SilenceUsage = true
+		return fmt.Errorf("%d error(s) found in config", len(validation.Errors))
+	}
+	fmt.Println("The config is valid.")
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(NewValidateCmd())
+}
@@ -0,0 +1,151 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+const baselineVersion = 1
+
+// Baseline records known rule check failures, so a plan could report only failures that are not in it.
+type Baseline struct {
+	Version     int                  `json:"version"`
+	FailedRules []baselineFailedRule `json:"failed_rules"`
+}
+
+type baselineFailedRule struct {
+	Address    string              `json:"address"`
+	Violations []baselineViolation `json:"violations"`
+	Error      string              `json:"error"`
+}
+
+// baselineViolation is a single violation of a rule, Key identifies a finding inside the file for rules that report findings, like `no_secrets`.
+// For rules that don't know which files violate them, File is empty and Key is a fingerprint of the check error.
+type baselineViolation struct {
+	File string `json:"file,omitempty"`
+	Key  string `json:"key,omitempty"`
+}
+
+// violatingFilesRule is implemented by rules that know which files violate them, each file is a violation in the baseline.
+type violatingFilesRule interface {
+	violatingFiles() []string
+}
+
+// violationsRule is implemented by rules that report findings, each finding is a violation in the baseline.
+type violationsRule interface {
+	violations() []baselineViolation
+}
+
+// ruleViolations returns violations of the failed rule, the check error is not a part of them when the rule knows its violations,
+// so fixing one of the violations or line number changes in the error don't turn the rule into a new failure.
+// Otherwise the check error is the only violation, so a rule that fails in another way is a new failure.
+func ruleViolations(fr *FailedRule) []baselineViolation {
+	var violations []baselineViolation
+	if vr, ok := fr.Rule.(violationsRule); ok {
+		violations = vr.violations()
+	} else if vr, ok := fr.Rule.(violatingFilesRule); ok {
+		for _, file := range vr.violatingFiles() {
+			violations = append(violations, baselineViolation{File: file})
+		}
+	}
+	if len(violations) == 0 {
+		return []baselineViolation{{Key: fmt.Sprintf("%x", sha256.Sum256([]byte(fr.CheckError.Error())))}}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		if violations[i].File != violations[j].File {
+			return violations[i].File < violations[j].File
+		}
+		return violations[i].Key < violations[j].Key
+	})
+	return violations
+}
+
+// WriteBaseline writes all failed rules in the plan to a baseline file.
+func (p *GreptPlan) WriteBaseline(baselinePath string) error {
+	b := Baseline{
+		Version:     baselineVersion,
+		FailedRules: []baselineFailedRule{},
+	}
+	for _, fr := range p.FailedRules {
+		b.FailedRules = append(b.FailedRules, baselineFailedRule{
+			Address:    fr.Address(),
+			Violations: ruleViolations(fr),
+			Error:      fr.CheckError.Error(),
+		})
+	}
+	sort.Slice(b.FailedRules, func(i, j int) bool {
+		return b.FailedRules[i].Address < b.FailedRules[j].Address
+	})
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling baseline: %+v", err)
+	}
+	return afero.WriteFile(FsFactory(), baselinePath, content, 0644)
+}
+
+// LoadBaseline reads a baseline file written by WriteBaseline.
+func LoadBaseline(baselinePath string) (*Baseline, error) {
+	content, err := afero.ReadFile(FsFactory(), baselinePath)
+	if err != nil {
+		return nil, err
+	}
+	b := new(Baseline)
+	if err = json.Unmarshal(content, b); err != nil {
+		return nil, fmt.Errorf("invalid baseline file %s: %+v", baselinePath, err)
+	}
+	if b.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline file version %d, expected %d", b.Version, baselineVersion)
+	}
+	return b, nil
+}
+
+// ExcludeBaseline moves failed rules whose violations are all in the baseline to BaselinedRules, fixes that are only linked to these rules are removed from the plan.
+func (p *GreptPlan) ExcludeBaseline(b *Baseline) {
+	known := make(map[baselineKey]bool)
+	for _, r := range b.FailedRules {
+		for _, v := range r.Violations {
+			known[baselineKey{address: r.Address, violation: v}] = true
+		}
+	}
+	var failedRules []*FailedRule
+	remaining := make(map[string]bool)
+	for _, fr := range p.FailedRules {
+		if allKnown(known, fr) {
+			p.BaselinedRules = append(p.BaselinedRules, fr)
+			continue
+		}
+		failedRules = append(failedRules, fr)
+		remaining[fr.Id()] = true
+	}
+	p.FailedRules = failedRules
+	for id, fix := range p.Fixes {
+		linked := false
+		for _, ruleId := range fix.GetRuleIds() {
+			if remaining[ruleId] {
+				linked = true
+				break
+			}
+		}
+		if !linked {
+			delete(p.Fixes, id)
+		}
+	}
+}
+
+type baselineKey struct {
+	address   string
+	violation baselineViolation
+}
+
+func allKnown(known map[baselineKey]bool, fr *FailedRule) bool {
+	for _, v := range ruleViolations(fr) {
+		if !known[baselineKey{address: fr.Address(), violation: v}] {
+			return false
+		}
+	}
+	return true
+}
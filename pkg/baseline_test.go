@@ -0,0 +1,140 @@
+package pkg
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *greptConfigSuite) TestBaseline_OnlyNewFailuresShouldBeReported() {
+	t := s.T()
+	content := `
+	rule "file_content" no_todo {
+		glob = "/src/*.go"
+		literal = ["TODO"]
+		mode = "must_not_match"
+	}
+
+	rule "must_be_true" license {
+		condition = false
+	}
+
+	fix "local_file" license {
+		rule_ids = [rule.must_be_true.license.id]
+		paths = ["/LICENSE"]
+		content = "MIT"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl", "/src/a.go"}, []string{content, "// TODO"})
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 2)
+	require.NoError(t, plan.WriteBaseline("/baseline.json"))
+
+	baseline, err := LoadBaseline("/baseline.json")
+	require.NoError(t, err)
+	plan, err = RunGreptPlan(config)
+	require.NoError(t, err)
+	plan.ExcludeBaseline(baseline)
+	s.Empty(plan.FailedRules)
+	s.Len(plan.BaselinedRules, 2)
+	s.Empty(plan.Fixes)
+
+	// a new file that violates the rule is a new violation
+	require.NoError(t, afero.WriteFile(s.fs, "/src/b.go", []byte("// TODO"), 0644))
+	config, err = BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err = RunGreptPlan(config)
+	require.NoError(t, err)
+	plan.ExcludeBaseline(baseline)
+	require.Len(t, plan.FailedRules, 1)
+	s.Equal("rule.file_content.no_todo", plan.FailedRules[0].Address())
+	s.Len(plan.BaselinedRules, 1)
+}
+
+func (s *greptConfigSuite) TestBaseline_FixedOrMovedViolationsShouldNotBeReported() {
+	t := s.T()
+	content := `
+	rule "file_content" no_todo {
+		glob = "/src/*.go"
+		literal = ["TODO"]
+		mode = "must_not_match"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl", "/src/a.go", "/src/b.go"}, []string{content, "// TODO", "// TODO"})
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	require.NoError(t, plan.WriteBaseline("/baseline.json"))
+	baseline, err := LoadBaseline("/baseline.json")
+	require.NoError(t, err)
+
+	// one of the baselined violations is fixed, the other one moves to another line
+	require.NoError(t, s.fs.Remove("/src/a.go"))
+	require.NoError(t, afero.WriteFile(s.fs, "/src/b.go", []byte("package src\n\n// TODO"), 0644))
+	config, err = BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err = RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	plan.ExcludeBaseline(baseline)
+	s.Empty(plan.FailedRules)
+	s.Len(plan.BaselinedRules, 1)
+}
+
+func (s *greptConfigSuite) TestBaseline_NewViolationsOfFileExistRuleShouldBeReported() {
+	t := s.T()
+	content := `
+	rule "file_exist" no_tmp {
+		glob          = "/src/*.tmp"
+		fail_on_exist = true
+	}
+
+	rule "file_exist" docs {
+		glob      = "/docs/*.md"
+		min_count = 2
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl", "/src/a.tmp", "/docs/a.md"}, []string{content, "", ""})
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 2)
+	require.NoError(t, plan.WriteBaseline("/baseline.json"))
+	baseline, err := LoadBaseline("/baseline.json")
+	require.NoError(t, err)
+
+	// new files that should not exist are new violations
+	require.NoError(t, afero.WriteFile(s.fs, "/src/new1.tmp", []byte(""), 0644))
+	require.NoError(t, afero.WriteFile(s.fs, "/src/new2.tmp", []byte(""), 0644))
+	config, err = BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err = RunGreptPlan(config)
+	require.NoError(t, err)
+	plan.ExcludeBaseline(baseline)
+	require.Len(t, plan.FailedRules, 1)
+	s.Equal("rule.file_exist.no_tmp", plan.FailedRules[0].Address())
+	require.Len(t, plan.BaselinedRules, 1)
+	s.Equal("rule.file_exist.docs", plan.BaselinedRules[0].Address())
+
+	// a rule without violating files fails with another check error
+	require.NoError(t, s.fs.Remove("/docs/a.md"))
+	config, err = BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err = RunGreptPlan(config)
+	require.NoError(t, err)
+	plan.ExcludeBaseline(baseline)
+	s.Len(plan.FailedRules, 2)
+	s.Empty(plan.BaselinedRules)
+}
+
+func (s *greptConfigSuite) TestBaseline_UnsupportedVersion() {
+	s.dummyFsWithFiles([]string{"/baseline.json"}, []string{`{"version": 2, "failed_rules": []}`})
+	_, err := LoadBaseline("/baseline.json")
+	s.NotNil(err)
+	s.Contains(err.Error(), "unsupported baseline file version")
+}
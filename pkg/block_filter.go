@@ -0,0 +1,313 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// BlockFilter selects a subset of rules and fixes in the config. A selector could be a block address that might contain wildcards like `rule.file_hash.*` or `fix.local_file.license`,
+// `tag:<tag>` or `severity:<severity>` for rules.
+// Only selected rules, fixes linked to them through `rule_ids`, and the blocks they depend on would be evaluated.
+type BlockFilter struct {
+	Targets  []string
+	Excludes []string
+}
+
+func (f *BlockFilter) empty() bool {
+	return f == nil || (len(f.Targets) == 0 && len(f.Excludes) == 0)
+}
+
+// blockSelection holds addresses of selected rule and fix blocks, without `for_each` keys.
+type blockSelection struct {
+	filter *BlockFilter
+	rules  map[string]bool
+	fixes  map[string]bool
+}
+
+// filter returns the selected rule and fix blocks along with all blocks they depend on, `variable` blocks are always kept.
+func (f *BlockFilter) filter(blocks []*golden.HclBlock) ([]*golden.HclBlock, *blockSelection, error) {
+	byAddress := make(map[string]*golden.HclBlock)
+	for _, b := range blocks {
+		byAddress[referenceAddress(b)] = b
+	}
+	targets, excludes := blockSelectors(f.Targets, true), blockSelectors(f.Excludes, false)
+	needMeta := false
+	for _, selector := range append(f.Targets, f.Excludes...) {
+		if strings.HasPrefix(selector, "tag:") || strings.HasPrefix(selector, "severity:") {
+			needMeta = true
+		}
+	}
+	ruleMeta := func(b *golden.HclBlock) ([]string, string, error) {
+		if !needMeta {
+			return nil, "", nil
+		}
+		return staticRuleMeta(b)
+	}
+	selection := &blockSelection{
+		filter: f,
+		rules:  make(map[string]bool),
+		fixes:  make(map[string]bool),
+	}
+	directRules := make(map[string]bool)
+	matchedTargets := make(map[string]bool)
+	for _, b := range blocks {
+		address := referenceAddress(b)
+		switch b.Type {
+		case "rule":
+			tags, severity, err := ruleMeta(b)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(targets) == 0 {
+				directRules[address] = true
+				continue
+			}
+			for _, t := range targets {
+				if match, err := selectorsMatch([]string{t}, address, tags, severity); err != nil {
+					return nil, nil, err
+				} else if match {
+					matchedTargets[t] = true
+					directRules[address] = true
+				}
+			}
+		case "fix":
+			for _, t := range targets {
+				if match, err := selectorsMatch([]string{t}, address, nil, ""); err != nil {
+					return nil, nil, err
+				} else if match {
+					matchedTargets[t] = true
+					selection.fixes[address] = true
+					// rules that a targeted fix is linked to are selected too
+					for _, ref := range ruleIdsReferences(b) {
+						if _, ok := byAddress[ref]; ok {
+							selection.rules[ref] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, t := range targets {
+		if !matchedTargets[t] {
+			return nil, nil, fmt.Errorf("target %s matches no rule or fix", t)
+		}
+	}
+	for address := range directRules {
+		selection.rules[address] = true
+	}
+	excluded := make(map[string]bool)
+	for _, b := range blocks {
+		address := referenceAddress(b)
+		var tags []string
+		severity := ""
+		if b.Type == "rule" {
+			var err error
+			if tags, severity, err = ruleMeta(b); err != nil {
+				return nil, nil, err
+			}
+		}
+		match, err := selectorsMatch(excludes, address, tags, severity)
+		if err != nil {
+			return nil, nil, err
+		}
+		if match {
+			excluded[address] = true
+		}
+	}
+	for _, b := range blocks {
+		if b.Type != "fix" {
+			continue
+		}
+		for _, ref := range ruleIdsReferences(b) {
+			if directRules[ref] && !excluded[ref] {
+				selection.fixes[referenceAddress(b)] = true
+			}
+		}
+	}
+	for address := range excluded {
+		delete(selection.rules, address)
+		delete(selection.fixes, address)
+	}
+
+	required := make(map[string]bool)
+	var pending []string
+	for _, b := range blocks {
+		address := referenceAddress(b)
+		if selection.rules[address] || selection.fixes[address] || b.Type == "variable" {
+			pending = append(pending, address)
+		}
+	}
+	for len(pending) > 0 {
+		address := pending[0]
+		pending = pending[1:]
+		b, ok := byAddress[address]
+		if !ok || required[address] {
+			continue
+		}
+		required[address] = true
+		pending = append(pending, references(b.Body)...)
+	}
+	var r []*golden.HclBlock
+	for _, b := range blocks {
+		if required[referenceAddress(b)] {
+			r = append(r, b)
+		}
+	}
+	return r, selection, nil
+}
+
+// blockSelectors handles selectors for `for_each` instances like `rule.file_hash.license[a]`, since blocks have not been expanded yet.
+// With keep, the key is removed so the whole block would be selected, otherwise these selectors are dropped. They're applied to instances after plan.
+func blockSelectors(selectors []string, keep bool) []string {
+	var r []string
+	for _, s := range selectors {
+		if !strings.HasSuffix(s, "]") {
+			r = append(r, s)
+			continue
+		}
+		if keep {
+			blockAddress, _, _ := strings.Cut(s, "[")
+			r = append(r, blockAddress)
+		}
+	}
+	return r
+}
+
+// selected returns true if the expanded block is selected, `for_each` instance selectors are only checked here.
+func (s *blockSelection) selected(address string, blocks map[string]bool, tags []string, severity string) (bool, error) {
+	if s == nil {
+		return true, nil
+	}
+	blockAddress, _, _ := strings.Cut(address, "[")
+	if !blocks[blockAddress] {
+		return false, nil
+	}
+	excluded, err := selectorsMatch(s.filter.Excludes, address, tags, severity)
+	if err != nil || excluded {
+		return false, err
+	}
+	var instanceTargets []string
+	for _, t := range s.filter.Targets {
+		if tb, _, _ := strings.Cut(t, "["); strings.HasSuffix(t, "]") && tb == blockAddress {
+			instanceTargets = append(instanceTargets, t)
+		}
+	}
+	if len(instanceTargets) == 0 {
+		return true, nil
+	}
+	return selectorsMatch(instanceTargets, address, tags, severity)
+}
+
+func (s *blockSelection) ruleSelected(r Rule) (bool, error) {
+	if s == nil {
+		return true, nil
+	}
+	return s.selected(r.Address(), s.rules, r.GetTags(), r.GetSeverity())
+}
+
+func (s *blockSelection) fixSelected(f Fix) (bool, error) {
+	if s == nil {
+		return true, nil
+	}
+	return s.selected(f.Address(), s.fixes, nil, "")
+}
+
+// referenceAddress returns the address that other blocks use to reference the block, like `data.http.foo`, `local.foo` or `var.foo`.
+func referenceAddress(b *golden.HclBlock) string {
+	switch b.Type {
+	case "local":
+		return "local." + b.Labels[1]
+	case "variable":
+		return "var." + b.Labels[0]
+	}
+	return b.Type + "." + strings.Join(b.Labels, ".")
+}
+
+// references returns addresses of all blocks referenced by expressions in the body.
+func references(body *hclsyntax.Body) []string {
+	var r []string
+	for _, attr := range body.Attributes {
+		for _, traversal := range attr.Expr.Variables() {
+			if ref := traversalAddress(traversal); ref != "" {
+				r = append(r, ref)
+			}
+		}
+	}
+	for _, nb := range body.Blocks {
+		r = append(r, references(nb.Body)...)
+	}
+	return r
+}
+
+func ruleIdsReferences(b *golden.HclBlock) []string {
+	attr, ok := b.Body.Attributes["rule_ids"]
+	if !ok {
+		return nil
+	}
+	var r []string
+	for _, traversal := range attr.Expr.Variables() {
+		if ref := traversalAddress(traversal); strings.HasPrefix(ref, "rule.") {
+			r = append(r, ref)
+		}
+	}
+	return r
+}
+
+func traversalAddress(traversal hcl.Traversal) string {
+	var names []string
+	for _, t := range traversal {
+		if root, ok := t.(hcl.TraverseRoot); ok {
+			names = append(names, root.Name)
+			continue
+		}
+		attr, ok := t.(hcl.TraverseAttr)
+		if !ok {
+			break
+		}
+		names = append(names, attr.Name)
+	}
+	length := 0
+	switch traversal.RootName() {
+	case "local", "var":
+		length = 2
+	case "data", "rule", "fix":
+		length = 3
+	default:
+		return ""
+	}
+	if len(names) < length {
+		return ""
+	}
+	return strings.Join(names[:length], ".")
+}
+
+// staticRuleMeta returns `tags` and `severity` of a rule block without evaluation, so they must be literal values to be selected by.
+func staticRuleMeta(b *golden.HclBlock) ([]string, string, error) {
+	var tags []string
+	severity := SeverityError
+	if attr, ok := b.Body.Attributes["tags"]; ok {
+		v, diag := attr.Expr.Value(nil)
+		if diag.HasErrors() || !v.IsWhollyKnown() || v.IsNull() || !v.CanIterateElements() {
+			return nil, "", fmt.Errorf("`tags` of %s must be a list of literal strings to select rules: %s", referenceAddress(b), b.Range().String())
+		}
+		for _, tag := range v.AsValueSlice() {
+			if tag.Type() != cty.String || tag.IsNull() {
+				return nil, "", fmt.Errorf("`tags` of %s must be a list of literal strings to select rules: %s", referenceAddress(b), b.Range().String())
+			}
+			tags = append(tags, tag.AsString())
+		}
+	}
+	if attr, ok := b.Body.Attributes["severity"]; ok {
+		v, diag := attr.Expr.Value(nil)
+		if diag.HasErrors() || v.Type() != cty.String || v.IsNull() || !v.IsKnown() {
+			return nil, "", fmt.Errorf("`severity` of %s must be a literal string to select rules: %s", referenceAddress(b), b.Range().String())
+		}
+		severity = v.AsString()
+	}
+	return tags, severity, nil
+}
@@ -0,0 +1,148 @@
+package pkg
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type blockFilterSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestBlockFilterSuite(t *testing.T) {
+	suite.Run(t, new(blockFilterSuite))
+}
+
+func (s *blockFilterSuite) SetupTest() {
+	s.testBase = newTestBase()
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{`
+	locals {
+		items   = toset(["a", "b"])
+		license = rule.must_be_true.dependency.condition
+	}
+
+	rule "must_be_true" license {
+		condition = local.license
+		tags      = ["legal"]
+	}
+
+	rule "must_be_true" dependency {
+		condition = false
+	}
+
+	rule "must_be_true" readme {
+		for_each  = local.items
+		condition = false
+		tags      = ["docs"]
+	}
+
+	rule "must_be_true" broken {
+		condition = tobool("not a bool")
+	}
+
+	fix "local_file" license {
+		rule_ids = [rule.must_be_true.license.id]
+		paths    = ["/LICENSE"]
+		content  = "MIT"
+	}
+
+	fix "local_file" readme {
+		for_each = local.items
+		rule_ids = [rule.must_be_true.readme[each.value].id]
+		paths    = ["/${each.value}.md"]
+		content  = each.value
+	}
+	`})
+}
+
+func (s *blockFilterSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *blockFilterSuite) TestFilter() {
+	cases := []struct {
+		desc      string
+		filter    *BlockFilter
+		wantRules []string
+		wantFixes []string
+	}{
+		{
+			desc:      "target_rule_with_dependencies",
+			filter:    &BlockFilter{Targets: []string{"rule.must_be_true.license"}},
+			wantRules: []string{"rule.must_be_true.license"},
+			wantFixes: []string{"fix.local_file.license"},
+		},
+		{
+			desc:      "target_by_tag",
+			filter:    &BlockFilter{Targets: []string{"tag:docs"}},
+			wantRules: []string{"rule.must_be_true.readme[a]", "rule.must_be_true.readme[b]"},
+			wantFixes: []string{"fix.local_file.readme[a]", "fix.local_file.readme[b]"},
+		},
+		{
+			desc:      "target_for_each_instance",
+			filter:    &BlockFilter{Targets: []string{`rule.must_be_true.readme["a"]`}},
+			wantRules: []string{"rule.must_be_true.readme[a]"},
+			wantFixes: []string{"fix.local_file.readme[a]"},
+		},
+		{
+			desc:      "target_fix",
+			filter:    &BlockFilter{Targets: []string{"fix.local_file.license"}},
+			wantRules: []string{"rule.must_be_true.license"},
+			wantFixes: []string{"fix.local_file.license"},
+		},
+		{
+			desc: "exclude",
+			filter: &BlockFilter{
+				Excludes: []string{"rule.must_be_true.broken", "fix.local_file.readme[b]", "tag:legal"},
+			},
+			wantRules: []string{"rule.must_be_true.dependency", "rule.must_be_true.readme[a]", "rule.must_be_true.readme[b]"},
+			wantFixes: []string{"fix.local_file.readme[a]"},
+		},
+		{
+			desc: "target_with_wildcard_and_exclude",
+			filter: &BlockFilter{
+				Targets:  []string{"rule.must_be_true.*"},
+				Excludes: []string{"rule.must_be_true.broken", "rule.must_be_true.readme"},
+			},
+			wantRules: []string{"rule.must_be_true.dependency", "rule.must_be_true.license"},
+			wantFixes: []string{"fix.local_file.license"},
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			t := s.T()
+			config, err := BuildFilteredGreptConfig("", "/cfg", nil, nil, c.filter)
+			require.NoError(t, err)
+			plan, err := RunGreptPlan(config)
+			require.NoError(t, err)
+			var rules, fixes []string
+			for _, fr := range plan.FailedRules {
+				rules = append(rules, fr.Address())
+			}
+			for _, f := range plan.Fixes {
+				fixes = append(fixes, f.Address())
+			}
+			sort.Strings(rules)
+			sort.Strings(fixes)
+			s.Equal(c.wantRules, rules)
+			s.Equal(c.wantFixes, fixes)
+		})
+	}
+}
+
+func (s *blockFilterSuite) TestFilter_UnmatchedTarget() {
+	_, err := BuildFilteredGreptConfig("", "/cfg", nil, nil, &BlockFilter{Targets: []string{"rule.file_hash.*"}})
+	s.ErrorContains(err, "target rule.file_hash.* matches no rule or fix")
+}
+
+func (s *blockFilterSuite) TestFilter_NoFilterEvaluatesAllBlocks() {
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	if err == nil {
+		_, err = RunGreptPlan(config)
+	}
+	s.Error(err)
+}
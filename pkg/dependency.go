@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// validateDependencies checks that `depends_on` of all blocks are lists of existing block addresses, and there's no dependency cycle between blocks,
+// so an invalid order, like two fixes that depend on each other, is reported when the config is loaded instead of when fixes are applied.
+func validateDependencies(blocks []*golden.HclBlock) error {
+	byAddress := make(map[string]*golden.HclBlock)
+	for _, b := range blocks {
+		byAddress[referenceAddress(b)] = b
+	}
+	var err error
+	for _, b := range blocks {
+		dependsOn, ok := b.Body.Attributes["depends_on"]
+		if !ok {
+			continue
+		}
+		address := referenceAddress(b)
+		tuple, ok := dependsOn.Expr.(*hclsyntax.TupleConsExpr)
+		if !ok {
+			err = multierror.Append(err, fmt.Errorf("`depends_on` of %s must be a list of block addresses: %s", address, dependsOn.SrcRange.String()))
+			continue
+		}
+		for _, expr := range tuple.Exprs {
+			traversal, diag := hcl.AbsTraversalForExpr(expr)
+			ref := ""
+			if !diag.HasErrors() {
+				ref = traversalAddress(traversal)
+			}
+			if ref == "" {
+				err = multierror.Append(err, fmt.Errorf("`depends_on` of %s must be a list of block addresses: %s", address, expr.Range().String()))
+				continue
+			}
+			if ref == address {
+				err = multierror.Append(err, fmt.Errorf("%s depends on itself: %s", address, expr.Range().String()))
+				continue
+			}
+			if _, exist := byAddress[ref]; !exist {
+				err = multierror.Append(err, fmt.Errorf("%s depends on %s, which is not declared: %s", address, ref, expr.Range().String()))
+			}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if cycle := dependencyCycle(blocks, byAddress); len(cycle) > 0 {
+		return fmt.Errorf("dependency cycle found, each block depends on the next one: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// dependencyCycle returns addresses of blocks in the first dependency cycle found, the first address is repeated at the end.
+func dependencyCycle(blocks []*golden.HclBlock, byAddress map[string]*golden.HclBlock) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	dependencies := make(map[string][]string)
+	var addresses []string
+	for _, b := range blocks {
+		address := referenceAddress(b)
+		addresses = append(addresses, address)
+		refs := make(map[string]bool)
+		for _, ref := range references(b.Body) {
+			if _, ok := byAddress[ref]; ok && ref != address {
+				refs[ref] = true
+			}
+		}
+		for ref := range refs {
+			dependencies[address] = append(dependencies[address], ref)
+		}
+		sort.Strings(dependencies[address])
+	}
+	sort.Strings(addresses)
+	state := make(map[string]int)
+	var path []string
+	var visit func(address string) []string
+	visit = func(address string) []string {
+		switch state[address] {
+		case visited:
+			return nil
+		case visiting:
+			for i, a := range path {
+				if a == address {
+					return append(append([]string{}, path[i:]...), address)
+				}
+			}
+		}
+		state[address] = visiting
+		path = append(path, address)
+		for _, dep := range dependencies[address] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[address] = visited
+		return nil
+	}
+	for _, address := range addresses {
+		if cycle := visit(address); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package pkg
+
+import (
+	"github.com/spf13/afero"
+)
+
+func (s *greptConfigSuite) TestValidateDependencies() {
+	cases := []struct {
+		desc    string
+		content string
+		want    string
+	}{
+		{
+			desc: "cycle between fixes",
+			content: `
+			fix "local_file" a {
+				rule_ids   = [rule.must_be_true.sample.id]
+				paths      = ["/a"]
+				content    = "a"
+				depends_on = [fix.local_file.b]
+			}
+
+			fix "local_file" b {
+				rule_ids   = [rule.must_be_true.sample.id]
+				paths      = ["/b"]
+				content    = "b"
+				depends_on = [fix.local_file.a]
+			}
+			`,
+			want: "dependency cycle found, each block depends on the next one: fix.local_file.a -> fix.local_file.b -> fix.local_file.a",
+		},
+		{
+			desc: "cycle through reference",
+			content: `
+			fix "local_file" a {
+				rule_ids   = [rule.must_be_true.sample.id]
+				paths      = ["/a"]
+				content    = fix.local_file.b.content
+			}
+
+			fix "local_file" b {
+				rule_ids   = [rule.must_be_true.sample.id]
+				paths      = ["/b"]
+				content    = "b"
+				depends_on = [fix.local_file.a]
+			}
+			`,
+			want: "fix.local_file.a -> fix.local_file.b -> fix.local_file.a",
+		},
+		{
+			desc: "depends on itself",
+			content: `
+			fix "local_file" a {
+				rule_ids   = [rule.must_be_true.sample.id]
+				paths      = ["/a"]
+				content    = "a"
+				depends_on = [fix.local_file.a]
+			}
+			`,
+			want: "fix.local_file.a depends on itself",
+		},
+		{
+			desc: "undeclared block",
+			content: `
+			fix "local_file" a {
+				rule_ids   = [rule.must_be_true.sample.id]
+				paths      = ["/a"]
+				content    = "a"
+				depends_on = [fix.rename_file.missing]
+			}
+			`,
+			want: "fix.local_file.a depends on fix.rename_file.missing, which is not declared",
+		},
+		{
+			desc: "not block address",
+			content: `
+			fix "local_file" a {
+				rule_ids   = [rule.must_be_true.sample.id]
+				paths      = ["/a"]
+				content    = "a"
+				depends_on = ["fix.local_file.b"]
+			}
+			`,
+			want: "`depends_on` of fix.local_file.a must be a list of block addresses",
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			s.fs = afero.NewMemMapFs()
+			s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{`
+			rule "must_be_true" sample {
+				condition = false
+			}
+			` + c.content})
+			_, err := BuildGreptConfig("", "/cfg", nil, nil)
+			s.NotNil(err)
+			s.Contains(err.Error(), c.want)
+		})
+	}
+}
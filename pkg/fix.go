@@ -5,6 +5,7 @@ import (
 
 	"github.com/Azure/golden"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/spf13/afero"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -14,13 +15,35 @@ type Fix interface {
 	// discriminator func
 	Fix()
 	setRuleIds([]string)
+	setTargetFs(afero.Fs)
+}
+
+// pathTouchingFix is implemented by fixes that know which paths they would change before apply.
+type pathTouchingFix interface {
+	touchedPaths() []string
+}
+
+// pathDeclaringFix is implemented by fixes that can't know which paths they would change, like `local_shell`, but users could declare them.
+type pathDeclaringFix interface {
+	declaredPaths() []string
+}
+
+// envDecodingFix is implemented by fixes whose attributes are rendered with their own env, like `local_shell`.
+type envDecodingFix interface {
+	decodeWithEnv() error
+}
+
+// pathReadingFix is implemented by fixes that read paths other than their touched paths.
+type pathReadingFix interface {
+	readPaths() []string
 }
 
 var _ golden.Valuable = &BaseFix{}
 var _ golden.BaseDecode = &BaseFix{}
 
 type BaseFix struct {
-	RuleIds []string `json:"rule_ids" hcl:"rule_ids"`
+	RuleIds  []string `json:"rule_ids" hcl:"rule_ids"`
+	targetFs afero.Fs
 }
 
 func (bf *BaseFix) Fix() {}
@@ -69,3 +92,26 @@ func (bf *BaseFix) CanExecutePrePlan() bool {
 func (bf *BaseFix) setRuleIds(ids []string) {
 	bf.RuleIds = ids
 }
+
+// fs returns the file system that the fix applies to, it's the one returned by FsFactory unless the fix is previewed on an in-memory copy.
+func (bf *BaseFix) fs() afero.Fs {
+	if bf != nil && bf.targetFs != nil {
+		return bf.targetFs
+	}
+	return FsFactory()
+}
+
+func (bf *BaseFix) setTargetFs(fs afero.Fs) {
+	bf.targetFs = fs
+}
+
+// touchedPaths returns paths that the fix might change, effects of fixes like `local_shell` can't be known in advance, so it returns the whole working tree unless paths are declared.
+func touchedPaths(f Fix) []string {
+	if pf, ok := f.(pathTouchingFix); ok {
+		return pf.touchedPaths()
+	}
+	if df, ok := f.(pathDeclaringFix); ok && len(df.declaredPaths()) > 0 {
+		return df.declaredPaths()
+	}
+	return []string{"."}
+}
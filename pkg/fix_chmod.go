@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+)
+
+var _ Fix = &ChmodFix{}
+
+type ChmodFix struct {
+	*golden.BaseBlock
+	*BaseFix
+	Paths  []string     `json:"paths" hcl:"paths"`
+	Mode   *fs.FileMode `json:"mode" hcl:"mode,optional" validate:"omitempty,file_mode,conflict_with=Add Remove,at_least_one_of=Mode Add Remove"`
+	Add    *fs.FileMode `json:"add" hcl:"add,optional" validate:"omitempty,file_mode,at_least_one_of=Mode Add Remove"`
+	Remove *fs.FileMode `json:"remove" hcl:"remove,optional" validate:"omitempty,file_mode,at_least_one_of=Mode Add Remove"`
+}
+
+func (c *ChmodFix) Type() string {
+	return "chmod"
+}
+
+func (c *ChmodFix) touchedPaths() []string {
+	return c.Paths
+}
+
+func (c *ChmodFix) Apply() error {
+	var mode, add, remove fs.FileMode
+	var err error
+	for _, m := range []struct {
+		octal   *fs.FileMode
+		decimal *fs.FileMode
+	}{
+		{c.Mode, &mode},
+		{c.Add, &add},
+		{c.Remove, &remove},
+	} {
+		if m.octal == nil {
+			continue
+		}
+		if *m.decimal, err = toDecimal(*m.octal); err != nil {
+			return fmt.Errorf("%s: %+v", c.Address(), err)
+		}
+	}
+	afs := c.fs()
+	for _, path := range c.Paths {
+		info, statErr := afs.Stat(path)
+		if statErr != nil {
+			err = multierror.Append(err, statErr)
+			continue
+		}
+		perm := info.Mode().Perm()
+		if c.Mode != nil {
+			perm = mode
+		}
+		perm = (perm | add) &^ remove
+		if chmodErr := afs.Chmod(path, perm); chmodErr != nil {
+			err = multierror.Append(err, chmodErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package pkg
+
+import (
+	"context"
+	iofs "io/fs"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type chmodFixSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestChmodFixSuite(t *testing.T) {
+	suite.Run(t, new(chmodFixSuite))
+}
+
+func (s *chmodFixSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *chmodFixSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *chmodFixSuite) TestChmodFix_Apply() {
+	mode700 := iofs.FileMode(700)
+	add111 := iofs.FileMode(111)
+	remove77 := iofs.FileMode(77)
+	cases := []struct {
+		desc string
+		fix  *ChmodFix
+		want iofs.FileMode
+	}{
+		{
+			desc: "mode",
+			fix:  &ChmodFix{Mode: &mode700},
+			want: 0700,
+		},
+		{
+			desc: "add",
+			fix:  &ChmodFix{Add: &add111},
+			want: 0755,
+		},
+		{
+			desc: "remove",
+			fix:  &ChmodFix{Remove: &remove77},
+			want: 0600,
+		},
+		{
+			desc: "add_and_remove",
+			fix:  &ChmodFix{Add: &add111, Remove: &remove77},
+			want: 0700,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			_ = afero.WriteFile(s.fs, "/scripts/build.sh", []byte(""), 0644)
+			_ = s.fs.Chmod("/scripts/build.sh", 0644)
+			c.fix.Paths = []string{"/scripts/build.sh"}
+			s.NoError(c.fix.Apply())
+			info, err := s.fs.Stat("/scripts/build.sh")
+			s.NoError(err)
+			s.Equal(c.want, info.Mode().Perm())
+		})
+	}
+}
+
+func (s *chmodFixSuite) TestChmodFix_FileNotExist() {
+	add111 := iofs.FileMode(111)
+	fix := &ChmodFix{
+		Paths: []string{"/not_exist.sh"},
+		Add:   &add111,
+	}
+	s.Error(fix.Apply())
+}
+
+func (s *chmodFixSuite) TestChmodFix_FixFileModeRule() {
+	t := s.T()
+	content := `
+	rule "file_mode" scripts {
+		glob       = "/scripts/*.sh"
+		executable = true
+	}
+
+	fix "chmod" scripts {
+		rule_ids = [rule.file_mode.scripts.id]
+		paths    = rule.file_mode.scripts.mismatched_files
+		add      = 0111
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl"}, []string{content})
+	_ = afero.WriteFile(s.fs, "/scripts/build.sh", []byte(""), 0755)
+	_ = afero.WriteFile(s.fs, "/scripts/test.sh", []byte(""), 0644)
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	require.Len(t, plan.Fixes, 1)
+	require.NoError(t, plan.Apply())
+	info, err := s.fs.Stat("/scripts/test.sh")
+	require.NoError(t, err)
+	s.Equal(iofs.FileMode(0755), info.Mode().Perm())
+}
+
+func (s *chmodFixSuite) TestChmodFix_ModeConflictsWithAdd() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "chmod" scripts {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/build.sh"]
+		mode     = 0755
+		add      = 0111
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/build.sh"}, []string{content, ""})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
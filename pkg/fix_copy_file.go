@@ -19,8 +19,16 @@ func (c *CopyFileFix) Type() string {
 	return "copy_file"
 }
 
+func (c *CopyFileFix) touchedPaths() []string {
+	return []string{c.Dest}
+}
+
+func (c *CopyFileFix) readPaths() []string {
+	return []string{c.Src}
+}
+
 func (c *CopyFileFix) Apply() error {
-	fs := FsFactory()
+	fs := c.fs()
 	file, err := fs.Open(c.Src)
 	if err != nil {
 		return fmt.Errorf("error on reading src %s %+v", c.Src, err)
@@ -23,8 +23,12 @@ func (g *GitIgnoreFix) Type() string {
 	return "git_ignore"
 }
 
+func (g *GitIgnoreFix) touchedPaths() []string {
+	return []string{".gitignore"}
+}
+
 func (g *GitIgnoreFix) Apply() error {
-	fs := FsFactory()
+	fs := g.fs()
 	gitIgnoreFile := ".gitignore"
 	exist, err := afero.Exists(fs, gitIgnoreFile)
 	if err != nil {
@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+)
+
+var _ Fix = &LicenseHeaderFix{}
+
+type LicenseHeaderFix struct {
+	*golden.BaseBlock
+	*BaseFix
+	Paths         []string       `json:"paths" hcl:"paths"`
+	Header        string         `json:"header" hcl:"header" validate:"required"`
+	Year          string         `json:"year" hcl:"year,optional"`
+	CommentStyles []CommentStyle `json:"comment_style" hcl:"comment_style,block"`
+}
+
+func (l *LicenseHeaderFix) Type() string {
+	return "license_header"
+}
+
+func (l *LicenseHeaderFix) touchedPaths() []string {
+	return l.Paths
+}
+
+func (l *LicenseHeaderFix) Apply() error {
+	header, err := newLicenseHeader(l.Address(), l.Header, l.CommentStyles)
+	if err != nil {
+		return err
+	}
+	year := l.Year
+	if year == "" {
+		year = strconv.Itoa(time.Now().Year())
+	}
+	fs := l.fs()
+	for _, path := range l.Paths {
+		if applyErr := l.applyFile(fs, header, path, year); applyErr != nil {
+			err = multierror.Append(err, applyErr)
+		}
+	}
+	return err
+}
+
+func (l *LicenseHeaderFix) applyFile(fs afero.Fs, header licenseHeader, path, year string) error {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return err
+	}
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return err
+	}
+	newContent, err := header.apply(path, content, year)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(content, newContent) {
+		return nil
+	}
+	return afero.WriteFile(fs, path, newContent, info.Mode().Perm())
+}
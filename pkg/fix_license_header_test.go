@@ -0,0 +1,143 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/golden"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type licenseHeaderFixSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestLicenseHeaderFixSuite(t *testing.T) {
+	suite.Run(t, new(licenseHeaderFixSuite))
+}
+
+func (s *licenseHeaderFixSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *licenseHeaderFixSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *licenseHeaderFixSuite) TestLicenseHeaderFix_Apply() {
+	cases := []struct {
+		desc    string
+		path    string
+		content string
+		want    string
+	}{
+		{
+			desc:    "prepend",
+			path:    "/main.go",
+			content: "package main\n",
+			want:    "// Copyright (c) 2024 Contoso Ltd.\n// SPDX-License-Identifier: MIT\n\npackage main\n",
+		},
+		{
+			desc:    "empty_file",
+			path:    "/main.tf",
+			content: "",
+			want:    "# Copyright (c) 2024 Contoso Ltd.\n# SPDX-License-Identifier: MIT\n",
+		},
+		{
+			desc:    "keep_shebang",
+			path:    "/run.sh",
+			content: "#!/bin/bash\necho hi\n",
+			want:    "#!/bin/bash\n# Copyright (c) 2024 Contoso Ltd.\n# SPDX-License-Identifier: MIT\n\necho hi\n",
+		},
+		{
+			desc:    "keep_encoding",
+			path:    "/main.py",
+			content: "# -*- coding: utf-8 -*-\nprint('hi')\n",
+			want:    "# -*- coding: utf-8 -*-\n# Copyright (c) 2024 Contoso Ltd.\n# SPDX-License-Identifier: MIT\n\nprint('hi')\n",
+		},
+		{
+			desc:    "keep_build_constraints",
+			path:    "/build_linux.go",
+			content: "//go:build linux\n\npackage main\n",
+			want:    "// Copyright (c) 2024 Contoso Ltd.\n// SPDX-License-Identifier: MIT\n\n//go:build linux\n\npackage main\n",
+		},
+		{
+			desc:    "update_and_keep_year",
+			path:    "/outdated.go",
+			content: "// Copyright (c) 2019-2021 Contoso Ltd.\n// Licensed under the Apache License, Version 2.0.\n//go:build linux\n\npackage main\n",
+			want:    "// Copyright (c) 2019-2021 Contoso Ltd.\n// SPDX-License-Identifier: MIT\n//go:build linux\n\npackage main\n",
+		},
+		{
+			desc:    "update_after_build_constraints",
+			path:    "/tags_first.go",
+			content: "//go:build linux\n\n// Copyright 2019 Contoso Ltd.\n\npackage main\n",
+			want:    "//go:build linux\n\n// Copyright (c) 2019 Contoso Ltd.\n// SPDX-License-Identifier: MIT\n\npackage main\n",
+		},
+		{
+			desc:    "crlf",
+			path:    "/main.tf",
+			content: "locals {}\r\n",
+			want:    "# Copyright (c) 2024 Contoso Ltd.\r\n# SPDX-License-Identifier: MIT\r\n\r\nlocals {}\r\n",
+		},
+		{
+			desc:    "up_to_date",
+			path:    "/main.go",
+			content: "// Copyright (c) 2020 Contoso Ltd.\n// SPDX-License-Identifier: MIT\npackage main\n",
+			want:    "// Copyright (c) 2020 Contoso Ltd.\n// SPDX-License-Identifier: MIT\npackage main\n",
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			_ = afero.WriteFile(s.fs, c.path, []byte(c.content), 0644)
+			fix := &LicenseHeaderFix{
+				Paths:  []string{c.path},
+				Header: testLicenseHeader,
+				Year:   "2024",
+			}
+			s.NoError(fix.Apply())
+			content, err := afero.ReadFile(s.fs, c.path)
+			s.NoError(err)
+			s.Equal(c.want, string(content))
+		})
+	}
+}
+
+func (s *licenseHeaderFixSuite) TestLicenseHeaderFix_FixLicenseHeaderRule() {
+	t := s.T()
+	content := `
+	rule "license_header" source {
+		glob   = "/src/**/*.{go,tf,py}"
+		header = <<EOT
+Copyright (c) {{year}} Contoso Ltd.
+SPDX-License-Identifier: MIT
+EOT
+	}
+
+	fix "license_header" source {
+		rule_ids = [rule.license_header.source.id]
+		paths    = rule.license_header.source.mismatched_files
+		header   = rule.license_header.source.header
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/src/main.go", "/src/modules/main.tf", "/src/tools/gen.py"}, []string{
+		content,
+		"package main\n",
+		"# Copyright (c) 2023 Contoso Ltd.\n# SPDX-License-Identifier: MIT\n",
+		"#!/usr/bin/env python\nprint('hi')\n",
+	})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	fixes := golden.Blocks[Fix](config)
+	require.Len(t, fixes, 1)
+	s.Equal([]string{"/src/main.go", "/src/tools/gen.py"}, fixes[0].(*LicenseHeaderFix).Paths)
+	require.NoError(t, plan.Apply())
+	plan, err = RunGreptPlan(config)
+	require.NoError(t, err)
+	s.Empty(plan.FailedRules)
+}
@@ -25,13 +25,17 @@ func (lf *LocalFileFix) Type() string {
 	return "local_file"
 }
 
+func (lf *LocalFileFix) touchedPaths() []string {
+	return lf.Paths
+}
+
 func (lf *LocalFileFix) Apply() error {
 	fm, err := toDecimal(*lf.Mode)
 	if err != nil {
 		return err
 	}
 
-	fs := FsFactory()
+	fs := lf.fs()
 	for _, path := range lf.Paths {
 		dir := filepath.Dir(path)
 		dirExists, dirCheckErr := afero.DirExists(fs, dir)
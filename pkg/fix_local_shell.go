@@ -26,24 +26,32 @@ type LocalShellFix struct {
 	RemoteScript   string            `hcl:"remote_script,optional" validate:"conflict_with=Inlines Script,at_least_one_of=Inlines Script RemoteScript,eq=|http_url"`
 	OnlyOn         []string          `hcl:"only_on,optional" validate:"all_string_in_slice=windows linux darwin openbsd netbsd freebsd dragonfly android solaris plan9"`
 	Env            map[string]string `hcl:"env,optional"`
+	// TouchedPaths are paths that the script would change, declared by user since they can't be known in advance.
+	TouchedPaths []string `hcl:"touched_paths,optional"`
 }
 
 func (l *LocalShellFix) Type() string {
 	return "local_shell"
 }
 
+func (l *LocalShellFix) declaredPaths() []string {
+	return l.TouchedPaths
+}
+
 var stopByOnlyOnStub = func() {}
 
-func (l *LocalShellFix) Apply() (err error) {
-	// user assigned env, must set these env then re-render all attributes
-	if len(l.Env) > 0 {
-		hclfuncs.GoroutineLocalEnv.Set(l.Env)
-		defer hclfuncs.GoroutineLocalEnv.Remove()
-		err := golden.Decode(l)
-		if err != nil {
-			return err
-		}
+// decodeWithEnv re-renders all attributes with user assigned env, it's called by the plan before any fix is applied,
+// since decoding changes the block and reads the config's shared eval context, which is not safe while fixes are applied in parallel.
+func (l *LocalShellFix) decodeWithEnv() error {
+	if len(l.Env) == 0 {
+		return nil
 	}
+	hclfuncs.GoroutineLocalEnv.Set(l.Env)
+	defer hclfuncs.GoroutineLocalEnv.Remove()
+	return golden.Decode(l)
+}
+
+func (l *LocalShellFix) Apply() (err error) {
 	if len(l.OnlyOn) > 0 && !linq.From(l.OnlyOn).Contains(runtime.GOOS) {
 		stopByOnlyOnStub()
 		return nil
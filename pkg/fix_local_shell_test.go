@@ -372,6 +372,61 @@ func (s *localExecFixSuite) TestLocalShellFix_ApplyFix_UserAssignedEnvShouldBeLo
 	assert.Contains(t, string(content1), "1")
 }
 
+// run with -race, fixes with env are decoded before they're applied in parallel
+func (s *localExecFixSuite) TestLocalShellFix_ParallelApplyWithUserAssignedEnv() {
+	t := s.T()
+	if runtime.GOOS == "windows" {
+		t.Skip("cannot run this test on windows")
+	}
+	temp0, err := createTempFile(t, "test_grept")
+	s.NoError(err)
+	defer func() {
+		_ = os.Remove(temp0.Name())
+	}()
+	temp1, err := createTempFile(t, "test_grept")
+	s.NoError(err)
+	defer func() {
+		_ = os.Remove(temp1.Name())
+	}()
+	hcl := fmt.Sprintf(`
+	rule "must_be_true" "example" {
+		condition = false
+	}
+
+	fix "local_shell" "example0" {
+		rule_ids      = [rule.must_be_true.example.id]
+		inlines       = ["echo \"${env("TMP_VAR")}\">%[1]s"]
+		touched_paths = ["%[1]s"]
+		env = {
+			TMP_VAR = "0"
+		}
+	}
+
+	fix "local_shell" "example1" {
+		rule_ids      = [rule.must_be_true.example.id]
+		inlines       = ["echo \"${env("TMP_VAR")}\">%[2]s"]
+		touched_paths = ["%[2]s"]
+		env = {
+			TMP_VAR = "1"
+		}
+	}
+`, temp0.Name(), temp1.Name())
+	s.dummyFsWithFiles([]string{"/example/test.grept.hcl"}, []string{hcl})
+	config, err := BuildGreptConfig("", "/example", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	plan.Parallelism = 2
+	err = plan.Apply()
+	require.NoError(t, err)
+	content0, err := os.ReadFile(temp0.Name())
+	require.NoError(t, err)
+	content1, err := os.ReadFile(temp1.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "0\n", string(content0))
+	assert.Equal(t, "1\n", string(content1))
+}
+
 func (s *localExecFixSuite) TestLocalShellFix_ApplyFix_scriptWithUserAssignedEnv() {
 	t := s.T()
 	if runtime.GOOS == "windows" {
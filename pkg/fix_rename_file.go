@@ -15,7 +15,11 @@ func (rf *RenameFileFix) Type() string {
 	return "rename_file"
 }
 
+func (rf *RenameFileFix) touchedPaths() []string {
+	return []string{rf.OldName, rf.NewName}
+}
+
 func (rf *RenameFileFix) Apply() error {
-	fs := FsFactory()
+	fs := rf.fs()
 	return fs.Rename(rf.OldName, rf.NewName)
 }
@@ -17,8 +17,12 @@ func (r *RmLocalFileFix) Type() string {
 	return "rm_local_file"
 }
 
+func (r *RmLocalFileFix) touchedPaths() []string {
+	return r.Paths
+}
+
 func (r *RmLocalFileFix) Apply() error {
-	fs := FsFactory()
+	fs := r.fs()
 	var err error
 	for _, path := range r.Paths {
 		removeErr := fs.RemoveAll(path)
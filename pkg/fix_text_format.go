@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"bytes"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+)
+
+var _ Fix = &TextFormatFix{}
+
+type TextFormatFix struct {
+	*golden.BaseBlock
+	*BaseFix
+	Paths                  []string `json:"paths" hcl:"paths"`
+	Editorconfig           bool     `json:"editorconfig" hcl:"editorconfig,optional"`
+	EndOfLine              string   `json:"end_of_line" hcl:"end_of_line,optional" validate:"omitempty,oneof=lf crlf cr"`
+	TrimTrailingWhitespace *bool    `json:"trim_trailing_whitespace" hcl:"trim_trailing_whitespace,optional"`
+	InsertFinalNewline     *bool    `json:"insert_final_newline" hcl:"insert_final_newline,optional"`
+	Charset                string   `json:"charset" hcl:"charset,optional" validate:"omitempty,oneof=utf-8 utf-8-bom"`
+	IndentStyle            string   `json:"indent_style" hcl:"indent_style,optional" validate:"omitempty,oneof=space tab"`
+	IndentSize             int      `json:"indent_size" hcl:"indent_size,optional" validate:"omitempty,min=1"`
+}
+
+func (t *TextFormatFix) Type() string {
+	return "text_format"
+}
+
+func (t *TextFormatFix) touchedPaths() []string {
+	return t.Paths
+}
+
+func (t *TextFormatFix) Apply() error {
+	format := textFormat{
+		EndOfLine:              t.EndOfLine,
+		TrimTrailingWhitespace: t.TrimTrailingWhitespace,
+		InsertFinalNewline:     t.InsertFinalNewline,
+		Charset:                t.Charset,
+		IndentStyle:            t.IndentStyle,
+		IndentSize:             t.IndentSize,
+	}
+	if !t.Editorconfig && format.empty() {
+		return noTextFormatError(t.Address())
+	}
+	fs := t.fs()
+	loader := newEditorconfigLoader(fs)
+	var err error
+	for _, path := range t.Paths {
+		if formatErr := t.formatFile(fs, loader, path, format); formatErr != nil {
+			err = multierror.Append(err, formatErr)
+		}
+	}
+	return err
+}
+
+func (t *TextFormatFix) formatFile(fs afero.Fs, loader *editorconfigLoader, path string, format textFormat) error {
+	fileFormat, ok, err := resolveTextFormat(fs, loader, path, format, t.Editorconfig)
+	if err != nil || !ok {
+		return err
+	}
+	info, err := fs.Stat(path)
+	if err != nil {
+		return err
+	}
+	content, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return err
+	}
+	formatted := fileFormat.format(content)
+	if bytes.Equal(content, formatted) {
+		return nil
+	}
+	return afero.WriteFile(fs, path, formatted, info.Mode().Perm())
+}
@@ -0,0 +1,155 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type textFormatFixSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestTextFormatFixSuite(t *testing.T) {
+	suite.Run(t, new(textFormatFixSuite))
+}
+
+func (s *textFormatFixSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *textFormatFixSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *textFormatFixSuite) TestTextFormatFix_Apply() {
+	yes := true
+	no := false
+	cases := []struct {
+		desc    string
+		fix     *TextFormatFix
+		content string
+		want    string
+	}{
+		{
+			desc:    "end_of_line",
+			fix:     &TextFormatFix{EndOfLine: "lf"},
+			content: "a\r\nb\rc\n",
+			want:    "a\nb\nc\n",
+		},
+		{
+			desc:    "crlf",
+			fix:     &TextFormatFix{EndOfLine: "crlf"},
+			content: "a\nb\r\n",
+			want:    "a\r\nb\r\n",
+		},
+		{
+			desc:    "trim_trailing_whitespace",
+			fix:     &TextFormatFix{TrimTrailingWhitespace: &yes},
+			content: "a \t\r\nb  \r\n",
+			want:    "a\r\nb\r\n",
+		},
+		{
+			desc:    "insert_final_newline",
+			fix:     &TextFormatFix{InsertFinalNewline: &yes},
+			content: "a\r\nb",
+			want:    "a\r\nb\r\n",
+		},
+		{
+			desc:    "no_final_newline",
+			fix:     &TextFormatFix{InsertFinalNewline: &no},
+			content: "a\nb\n\n",
+			want:    "a\nb",
+		},
+		{
+			desc:    "remove_bom",
+			fix:     &TextFormatFix{Charset: "utf-8"},
+			content: "\xEF\xBB\xBFa\n",
+			want:    "a\n",
+		},
+		{
+			desc:    "add_bom",
+			fix:     &TextFormatFix{Charset: "utf-8-bom"},
+			content: "a\n",
+			want:    "\xEF\xBB\xBFa\n",
+		},
+		{
+			desc:    "tabs_to_spaces",
+			fix:     &TextFormatFix{IndentStyle: "space", IndentSize: 2},
+			content: "a:\n\tb:\n\t\tc: d\n",
+			want:    "a:\n  b:\n    c: d\n",
+		},
+		{
+			desc:    "spaces_to_tabs",
+			fix:     &TextFormatFix{IndentStyle: "tab"},
+			content: "func main() {\n    if true {\n          return\n    }\n}\n",
+			want:    "func main() {\n\tif true {\n\t\t  return\n\t}\n}\n",
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			_ = afero.WriteFile(s.fs, "/file.txt", []byte(c.content), 0644)
+			c.fix.Paths = []string{"/file.txt"}
+			s.NoError(c.fix.Apply())
+			content, err := afero.ReadFile(s.fs, "/file.txt")
+			s.NoError(err)
+			s.Equal(c.want, string(content))
+		})
+	}
+}
+
+func (s *textFormatFixSuite) TestTextFormatFix_BinaryFileUntouched() {
+	yes := true
+	_ = afero.WriteFile(s.fs, "/logo.png", []byte("\x89PNG\r\n\x1a\n  "), 0644)
+	fix := &TextFormatFix{
+		Paths:                  []string{"/logo.png"},
+		EndOfLine:              "lf",
+		TrimTrailingWhitespace: &yes,
+	}
+	s.NoError(fix.Apply())
+	content, err := afero.ReadFile(s.fs, "/logo.png")
+	s.NoError(err)
+	s.Equal("\x89PNG\r\n\x1a\n  ", string(content))
+}
+
+func (s *textFormatFixSuite) TestTextFormatFix_FixTextFormatRuleWithEditorconfig() {
+	t := s.T()
+	content := `
+	rule "text_format" src {
+		glob         = "/src/*"
+		editorconfig = true
+	}
+
+	fix "text_format" src {
+		rule_ids     = [rule.text_format.src.id]
+		paths        = rule.text_format.src.mismatched_files
+		editorconfig = true
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/.editorconfig", "/src/main.go", "/src/config.yml"}, []string{
+		content,
+		"root = true\n\n[*]\nend_of_line = lf\ninsert_final_newline = true\ntrim_trailing_whitespace = true\n\n[*.go]\nindent_style = tab\n\n[*.yml]\nindent_style = space\nindent_size = 2\n",
+		"package main\r\n\r\nfunc main() {\r\n    println() \r\n}",
+		"a:\n\tb: c\n",
+	})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	require.Len(t, plan.Fixes, 1)
+	require.NoError(t, plan.Apply())
+	goFile, err := afero.ReadFile(s.fs, "/src/main.go")
+	require.NoError(t, err)
+	s.Equal("package main\n\nfunc main() {\n\tprintln()\n}\n", string(goFile))
+	yamlFile, err := afero.ReadFile(s.fs, "/src/config.yml")
+	require.NoError(t, err)
+	s.Equal("a:\n  b: c\n", string(yamlFile))
+	plan, err = RunGreptPlan(config)
+	require.NoError(t, err)
+	s.Empty(plan.FailedRules)
+}
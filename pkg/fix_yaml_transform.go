@@ -31,8 +31,12 @@ func (y *YamlTransformFix) Type() string {
 	return "yaml_transform"
 }
 
+func (y *YamlTransformFix) touchedPaths() []string {
+	return []string{y.FilePath}
+}
+
 func (y *YamlTransformFix) Apply() error {
-	fs := FsFactory()
+	fs := y.fs()
 	yf, err := afero.ReadFile(fs, y.FilePath)
 	if err != nil {
 		return fmt.Errorf("error on reading yaml file %s, %+v fix.%s.%s %s", y.FilePath, err, y.Type(), y.Name(), y.HclBlock().Range().String())
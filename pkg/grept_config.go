@@ -16,6 +16,9 @@ var _ golden.Config = &GreptConfig{}
 
 type GreptConfig struct {
 	*golden.BaseConfig
+	cfgDir       string
+	selection    *blockSelection
+	suppressions []*Suppression
 }
 
 func NewGreptConfig(baseDir string, cliFlagAssignedVariables []golden.CliFlagAssignedVariables, ctx context.Context, hclBlocks []*golden.HclBlock) (*GreptConfig, error) {
@@ -26,27 +29,49 @@ func NewGreptConfig(baseDir string, cliFlagAssignedVariables []golden.CliFlagAss
 }
 
 func BuildGreptConfig(baseDir, cfgDir string, ctx context.Context, cliFlagAssignedVariables []golden.CliFlagAssignedVariables) (*GreptConfig, error) {
+	return BuildFilteredGreptConfig(baseDir, cfgDir, ctx, cliFlagAssignedVariables, nil)
+}
+
+// BuildFilteredGreptConfig builds config with only rules and fixes selected by the filter, and the blocks they depend on.
+func BuildFilteredGreptConfig(baseDir, cfgDir string, ctx context.Context, cliFlagAssignedVariables []golden.CliFlagAssignedVariables, filter *BlockFilter) (*GreptConfig, error) {
 	var err error
-	hclBlocks, err := loadGreptHclBlocks(false, cfgDir)
+	hclBlocks, suppressions, err := loadGreptHclBlocks(false, cfgDir)
 	if err != nil {
 		return nil, err
 	}
+	if err = validateDependencies(hclBlocks); err != nil {
+		return nil, err
+	}
+	ignored, err := loadGreptIgnore(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("error loading %s: %+v", greptIgnoreFile, err)
+	}
+	var selection *blockSelection
+	if !filter.empty() {
+		if hclBlocks, selection, err = filter.filter(hclBlocks); err != nil {
+			return nil, err
+		}
+	}
 
 	c, err := NewGreptConfig(baseDir, cliFlagAssignedVariables, ctx, hclBlocks)
 	if err != nil {
 		return nil, err
 	}
+	c.cfgDir = cfgDir
+	c.selection = selection
+	c.suppressions = append(suppressions, ignored...)
 	return c, nil
 }
 
-func loadGreptHclBlocks(ignoreUnsupportedBlock bool, dir string) ([]*golden.HclBlock, error) {
+// loadGreptHclBlocks returns blocks for golden, and suppressions which are not evaluated with the config.
+func loadGreptHclBlocks(ignoreUnsupportedBlock bool, dir string) ([]*golden.HclBlock, []*Suppression, error) {
 	fs := FsFactory()
 	matches, err := afero.Glob(fs, filepath.Join(dir, "*.grept.hcl"))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(matches) == 0 {
-		return nil, fmt.Errorf("no `.grept.hcl` file found at %s", dir)
+		return nil, nil, fmt.Errorf("no `.grept.hcl` file found at %s", dir)
 	}
 
 	var blocks []*golden.HclBlock
@@ -68,13 +93,23 @@ func loadGreptHclBlocks(ignoreUnsupportedBlock bool, dir string) ([]*golden.HclB
 		blocks = append(blocks, golden.AsHclBlocks(readBody.Blocks, writeBody.Blocks())...)
 	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var r []*golden.HclBlock
+	var suppressions []*Suppression
 
 	// First loop: parse all rule blocks
 	for _, b := range blocks {
+		if b.Type == "suppression" {
+			s, sErr := newSuppression(b.Block)
+			if sErr != nil {
+				err = multierror.Append(err, sErr)
+				continue
+			}
+			suppressions = append(suppressions, s)
+			continue
+		}
 		if golden.IsBlockTypeWanted(b.Type) {
 			r = append(r, b)
 			continue
@@ -83,5 +118,5 @@ func loadGreptHclBlocks(ignoreUnsupportedBlock bool, dir string) ([]*golden.HclB
 			err = multierror.Append(err, fmt.Errorf("invalid block type: %s %s", b.Type, b.Range().String()))
 		}
 	}
-	return r, err
+	return r, suppressions, err
 }
@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"github.com/Azure/golden"
 	"github.com/ahmetb/go-linq/v3"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 )
@@ -16,16 +20,27 @@ func RunGreptPlan(c *GreptConfig) (*GreptPlan, error) {
 
 	plan := newPlan(c)
 	for _, rb := range golden.Blocks[Rule](c) {
-		checkErr := rb.CheckError()
-		if checkErr == nil {
+		fr, sr, err := c.failedRule(rb)
+		if err != nil {
+			return nil, err
+		}
+		if sr != nil {
+			plan.addSuppressedRule(sr)
+			continue
+		}
+		if fr == nil {
 			continue
 		}
-		plan.addRule(&FailedRule{
-			Rule:       rb,
-			CheckError: checkErr,
-		})
+		plan.addRule(fr)
 		for _, fb := range golden.Blocks[Fix](c) {
-			if linq.From(fb.GetRuleIds()).Contains(rb.Id()) {
+			if !linq.From(fb.GetRuleIds()).Contains(rb.Id()) {
+				continue
+			}
+			selected, err := c.selection.fixSelected(fb)
+			if err != nil {
+				return nil, err
+			}
+			if selected {
 				plan.addFix(fb)
 			}
 		}
@@ -34,13 +49,60 @@ func RunGreptPlan(c *GreptConfig) (*GreptPlan, error) {
 	return plan, nil
 }
 
+// failedRule returns the failure of a selected rule, or the suppressed failure if the rule has a suppression. Both are nil if the rule passes or is not selected.
+func (c *GreptConfig) failedRule(rb Rule) (*FailedRule, *SuppressedRule, error) {
+	checkErr := rb.CheckError()
+	if checkErr == nil {
+		return nil, nil, nil
+	}
+	selected, err := c.selection.ruleSelected(rb)
+	if err != nil || !selected {
+		return nil, nil, err
+	}
+	fr := &FailedRule{
+		Rule:       rb,
+		CheckError: checkErr,
+	}
+	if s := suppressFindings(c.suppressions, rb); s != nil {
+		if rb.CheckError() == nil {
+			return nil, &SuppressedRule{
+				FailedRule:  fr,
+				Suppression: s,
+			}, nil
+		}
+		fr.CheckError = rb.CheckError()
+	}
+	if s := suppressionOf(c.suppressions, rb); s != nil {
+		if !s.expired() {
+			return nil, &SuppressedRule{
+				FailedRule:  fr,
+				Suppression: s,
+			}, nil
+		}
+		fr.CheckError = fmt.Errorf("%s, suppression at %s expired on %s", fr.CheckError.Error(), s.Range.String(), s.ExpiresOn)
+	}
+	return fr, nil, nil
+}
+
 var _ golden.Plan = &GreptPlan{}
 
 type GreptPlan struct {
 	FailedRules []*FailedRule
-	Fixes       map[string]Fix
+	// SuppressedRules are failed rules that have a suppression, they're not failures and their fixes are not applied.
+	SuppressedRules []*SuppressedRule
+	// BaselinedRules are failed rules that are known in the baseline, they're not reported as failures.
+	BaselinedRules []*FailedRule
+	Fixes          map[string]Fix
+	// Transactional makes Apply stop on the first failed fix and restore all paths changed by applied fixes.
+	Transactional bool
+	// Parallelism is the max number of fixes that Apply runs at the same time, fixes that touch the same paths are always applied one by one.
+	Parallelism int
 	c           *GreptConfig
-	mu          sync.Mutex
+	// savedFixes are fixes loaded from a saved plan file in the order they should be applied, there's no config for a loaded plan.
+	savedFixes []Fix
+	// savedDependencies are addresses of fixes that a loaded fix depends on, by fix address.
+	savedDependencies map[string]map[string]bool
+	mu                sync.Mutex
 }
 
 func newPlan(c *GreptConfig) *GreptPlan {
@@ -52,11 +114,20 @@ func newPlan(c *GreptConfig) *GreptPlan {
 
 func (p *GreptPlan) String() string {
 	sb := strings.Builder{}
-	for _, r := range p.FailedRules {
-		sb.WriteString(r.String())
+	for _, r := range p.sortedFailedRules() {
+		fmt.Fprintf(&sb, "[%s] %s", r.GetSeverity(), r.String())
+		if description := r.GetDescription(); description != "" {
+			fmt.Fprintf(&sb, "\n description: %s", description)
+		}
+		if url := r.GetRemediationUrl(); url != "" {
+			fmt.Fprintf(&sb, "\n remediation: %s", url)
+		}
 		sb.WriteString("\n---\n")
 	}
-	for _, f := range p.Fixes {
+	for _, sr := range p.sortedSuppressedRules() {
+		fmt.Fprintf(&sb, "[suppressed] %s\n---\n", sr.String())
+	}
+	for _, f := range p.sortedFixes() {
 		fmt.Fprintf(&sb, "%s would be apply:\n %s\n", f.Address(), golden.BlockToString(f))
 		sb.WriteString("\n---\n")
 	}
@@ -65,30 +136,158 @@ func (p *GreptPlan) String() string {
 }
 
 func (p *GreptPlan) Apply() error {
-	addresses := make(map[string]struct{})
-	for _, fix := range p.Fixes {
-		addresses[fix.Address()] = struct{}{}
+	if err := p.decodeFixes(); err != nil {
+		return err
 	}
-	if err := golden.Traverse[Fix](p.c.BaseConfig, func(fix Fix) error {
-		if _, ok := p.Fixes[fix.Id()]; !ok {
+	if p.Transactional {
+		return p.applyTransactional()
+	}
+	if err := p.applyFixes(func(fix Fix) error {
+		return fix.Apply()
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// applyTransactional snapshots paths that every fix touches before applying it, on the first error, the remaining fixes are skipped and all snapshots are restored in reverse order.
+func (p *GreptPlan) applyTransactional() error {
+	t := &transaction{fs: FsFactory()}
+	defer t.close()
+	var applyErr error
+	// fixes might run in parallel, conflicting fixes never run at the same time, so snapshots of running fixes don't overlap
+	var mu sync.Mutex
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return applyErr != nil
+	}
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if applyErr == nil {
+			applyErr = err
+		}
+	}
+	_ = p.applyFixes(func(fix Fix) error {
+		if failed() {
 			return nil
 		}
-		if decodeErr := golden.Decode(fix); decodeErr != nil {
-			return fmt.Errorf("rule.%s.%s(%s) decode error: %+v", fix.Type(), fix.Name(), fix.HclBlock().Range().String(), decodeErr)
+		if err := t.snapshot(touchedPaths(fix)); err != nil {
+			fail(fmt.Errorf("error on snapshot before applying %s: %+v", fix.Address(), err))
+			return nil
+		}
+		if err := fix.Apply(); err != nil {
+			fail(fmt.Errorf("error applying %s: %+v", fix.Address(), err))
 		}
 		return nil
-	}); err != nil {
+	})
+	if applyErr == nil {
+		return nil
+	}
+	if err := t.rollback(); err != nil {
+		return fmt.Errorf("%+v, and rollback failed, the working tree might be partially fixed: %+v", applyErr, err)
+	}
+	return fmt.Errorf("%+v, all changes have been rolled back", applyErr)
+}
+
+// transaction keeps snapshots taken before fixes are applied, so they could be rolled back in reverse order.
+type transaction struct {
+	fs        afero.Fs
+	mu        sync.Mutex
+	snapshots []*fsSnapshot
+	// wholeTree is true once the whole working tree has been snapshotted, restoring that snapshot reverts all later changes in the working tree,
+	// so later fixes that touch the whole working tree, like `local_shell` without `touched_paths`, don't copy it again.
+	wholeTree bool
+}
+
+func (t *transaction) snapshot(paths []string) error {
+	wholeTree := len(paths) == 1 && filepath.Clean(paths[0]) == "."
+	t.mu.Lock()
+	taken := wholeTree && t.wholeTree
+	t.mu.Unlock()
+	if taken {
+		return nil
+	}
+	snapshot, err := newFsSnapshot(t.fs, paths)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.snapshots = append(t.snapshots, snapshot)
+	t.wholeTree = t.wholeTree || wholeTree
+	return nil
+}
+
+func (t *transaction) rollback() error {
+	for i := len(t.snapshots) - 1; i >= 0; i-- {
+		if err := t.snapshots[i].restore(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *transaction) close() {
+	for _, snapshot := range t.snapshots {
+		_ = snapshot.close()
+	}
+}
+
+// traverseFixes calls fn with fixes in the plan in dependency order, it continues on error and returns all errors.
+func (p *GreptPlan) traverseFixes(fn func(fix Fix) error) error {
+	if p.c == nil {
+		var err error
+		for _, fix := range p.savedFixes {
+			if fixErr := fn(fix); fixErr != nil {
+				err = multierror.Append(err, fixErr)
+			}
+		}
 		return err
 	}
-	if err := golden.Traverse[Fix](p.c.BaseConfig, func(fix Fix) error {
+	return golden.Traverse[Fix](p.c.BaseConfig, func(fix Fix) error {
 		if _, ok := p.Fixes[fix.Id()]; !ok {
 			return nil
 		}
-		return fix.Apply()
-	}); err != nil {
-		return err
+		return fn(fix)
+	})
+}
+
+func (p *GreptPlan) decodeFixes() error {
+	if p.c == nil {
+		// fixes loaded from a saved plan have been decoded already
+		return nil
 	}
-	return nil
+	return p.traverseFixes(func(fix Fix) error {
+		decodeErr := golden.Decode(fix)
+		if ef, ok := fix.(envDecodingFix); ok && decodeErr == nil {
+			decodeErr = ef.decodeWithEnv()
+		}
+		if decodeErr != nil {
+			return fmt.Errorf("rule.%s.%s(%s) decode error: %+v", fix.Type(), fix.Name(), fix.HclBlock().Range().String(), decodeErr)
+		}
+		return nil
+	})
+}
+
+// BlockingRules returns failed rules that match any of the selectors. A selector could be a rule address that might contain wildcards like `rule.file_hash.*`, `tag:<tag>` or `severity:<severity>`.
+// Failed rules with `error` severity are blocking when no selector is given.
+func (p *GreptPlan) BlockingRules(selectors []string) ([]*FailedRule, error) {
+	if len(selectors) == 0 {
+		selectors = []string{"severity:" + SeverityError}
+	}
+	var blocking []*FailedRule
+	for _, fr := range p.FailedRules {
+		match, err := fr.matchAny(selectors)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			blocking = append(blocking, fr)
+		}
+	}
+	return blocking, nil
 }
 
 func (p *GreptPlan) addRule(fr *FailedRule) {
@@ -97,6 +296,12 @@ func (p *GreptPlan) addRule(fr *FailedRule) {
 	p.mu.Unlock()
 }
 
+func (p *GreptPlan) addSuppressedRule(sr *SuppressedRule) {
+	p.mu.Lock()
+	p.SuppressedRules = append(p.SuppressedRules, sr)
+	p.mu.Unlock()
+}
+
 func (p *GreptPlan) addFix(f Fix) {
 	p.mu.Lock()
 	p.Fixes[f.Id()] = f
@@ -108,6 +313,43 @@ type FailedRule struct {
 	CheckError error
 }
 
+func (fr *FailedRule) matchAny(selectors []string) (bool, error) {
+	return selectorsMatch(selectors, fr.Address(), fr.GetTags(), fr.GetSeverity())
+}
+
+// selectorsMatch returns true if a block matches any of the selectors. A selector could be a block address that might contain wildcards like `rule.file_hash.*`, `tag:<tag>` or `severity:<severity>`.
+func selectorsMatch(selectors []string, address string, tags []string, severity string) (bool, error) {
+	// address without `for_each` key, so `rule.file_hash.license` would match all its instances
+	blockAddress, _, _ := strings.Cut(address, "[")
+	for _, selector := range selectors {
+		if tag, ok := strings.CutPrefix(selector, "tag:"); ok {
+			if linq.From(tags).Contains(tag) {
+				return true, nil
+			}
+			continue
+		}
+		if s, ok := strings.CutPrefix(selector, "severity:"); ok {
+			if severity == s {
+				return true, nil
+			}
+			continue
+		}
+		// for_each keys are not quoted in block address, `rule.file_hash.license["a"]` equals to `rule.file_hash.license[a]`
+		selector = strings.NewReplacer(`["`, "[", `"]`, "]").Replace(selector)
+		if selector == address || selector == blockAddress {
+			return true, nil
+		}
+		match, err := path.Match(selector, blockAddress)
+		if err != nil {
+			return false, fmt.Errorf("invalid selector %s: %+v", selector, err)
+		}
+		if match {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (fr *FailedRule) String() string {
 	address := fr.Address()
 	return fmt.Sprintf("%s check return failure: %s", address, fr.CheckError.Error())
@@ -4,6 +4,7 @@ import "github.com/Azure/golden"
 
 func init() {
 	golden.MetaAttributeNames.Add("rule_ids")
+	golden.MetaAttributeNames.Add("severity", "description", "remediation_url", "tags")
 	golden.RegisterBaseBlock(func() golden.BlockType {
 		return new(BaseRule)
 	})
@@ -19,23 +20,42 @@ func init() {
 }
 
 func registerFix() {
-	golden.RegisterBlock(new(CopyFileFix))
-	golden.RegisterBlock(new(LocalFileFix))
-	golden.RegisterBlock(new(RenameFileFix))
-	golden.RegisterBlock(new(RmLocalFileFix))
-	golden.RegisterBlock(new(LocalShellFix))
-	golden.RegisterBlock(new(GitIgnoreFix))
-	golden.RegisterBlock(new(YamlTransformFix))
+	registerBlock(new(CopyFileFix))
+	registerBlock(new(LocalFileFix))
+	registerBlock(new(RenameFileFix))
+	registerBlock(new(RmLocalFileFix))
+	registerBlock(new(LocalShellFix))
+	registerBlock(new(GitIgnoreFix))
+	registerBlock(new(YamlTransformFix))
+	registerBlock(new(ChmodFix))
+	registerBlock(new(TextFormatFix))
+	registerBlock(new(LicenseHeaderFix))
 }
 
 func registerRule() {
-	golden.RegisterBlock(new(FileExistRule))
-	golden.RegisterBlock(new(FileHashRule))
-	golden.RegisterBlock(new(MustBeTrueRule))
-	golden.RegisterBlock(new(DirExistRule))
+	registerBlock(new(FileExistRule))
+	registerBlock(new(FileHashRule))
+	registerBlock(new(MustBeTrueRule))
+	registerBlock(new(DirExistRule))
+	registerBlock(new(FileContentRule))
+	registerBlock(new(StructuredValueRule))
+	registerBlock(new(JsonSchemaRule))
+	registerBlock(new(FileModeRule))
+	registerBlock(new(FileSizeRule))
+	registerBlock(new(TextFormatRule))
+	registerBlock(new(LicenseHeaderRule))
+	registerBlock(new(NoSecretsRule))
 }
 
 func registerData() {
-	golden.RegisterBlock(new(HttpDatasource))
-	golden.RegisterBlock(new(GitIgnoreDatasource))
+	registerBlock(new(HttpDatasource))
+	registerBlock(new(GitIgnoreDatasource))
+}
+
+// blockPrototypes are registered blocks keyed by `<block type>.<type>`, they're used to rebuild blocks from a saved plan.
+var blockPrototypes = make(map[string]golden.Block)
+
+func registerBlock(b golden.Block) {
+	golden.RegisterBlock(b)
+	blockPrototypes[b.BlockType()+"."+b.Type()] = b
 }
@@ -0,0 +1,267 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const yearPlaceholder = "{{year}}"
+
+// yearRegex matches a year, a year range like `2020-2024` or a year list like `2020, 2023`.
+var yearRegex = regexp.MustCompile(`\d{4}(?:\s*[-,]\s*\d{4})*`)
+
+var pythonEncodingRegex = regexp.MustCompile(`^[ \t\f]*#.*?coding[:=]`)
+
+// CommentStyle defines how the license header is commented out in files with certain extensions.
+type CommentStyle struct {
+	Extensions []string `json:"extensions" hcl:"extensions"`
+	LinePrefix string   `json:"line_prefix" hcl:"line_prefix,optional"`
+	Start      string   `json:"start" hcl:"start,optional"`
+	End        string   `json:"end" hcl:"end,optional"`
+}
+
+var defaultCommentStyles = []CommentStyle{
+	{
+		Extensions: []string{".go", ".js", ".jsx", ".ts", ".tsx", ".java", ".kt", ".c", ".h", ".cc", ".cpp", ".hpp", ".cs", ".rs", ".swift", ".scala", ".proto", ".bicep"},
+		LinePrefix: "//",
+	},
+	{
+		Extensions: []string{".tf", ".tfvars", ".hcl", ".py", ".sh", ".bash", ".ps1", ".rb", ".pl", ".r", ".yml", ".yaml", ".toml", "Dockerfile", "Makefile"},
+		LinePrefix: "#",
+	},
+	{
+		Extensions: []string{".sql", ".lua", ".hs"},
+		LinePrefix: "--",
+	},
+	{
+		Extensions: []string{".css"},
+		Start:      "/*",
+		LinePrefix: " *",
+		End:        " */",
+	},
+	{
+		Extensions: []string{".html", ".xml", ".md"},
+		Start:      "<!--",
+		End:        "-->",
+	},
+}
+
+// render returns the lines of the commented license header.
+func (s CommentStyle) render(template, year string) []string {
+	var lines []string
+	if s.Start != "" {
+		lines = append(lines, strings.TrimRight(s.Start, " \t"))
+	}
+	for _, line := range strings.Split(strings.TrimRight(template, "\r\n"), "\n") {
+		line = strings.ReplaceAll(strings.TrimRight(line, "\r"), yearPlaceholder, year)
+		if s.LinePrefix != "" {
+			line = s.LinePrefix + " " + line
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	if s.End != "" {
+		lines = append(lines, strings.TrimRight(s.End, " \t"))
+	}
+	return lines
+}
+
+// licenseHeader is the expected license header of files, `{{year}}` in the template matches any year.
+type licenseHeader struct {
+	template string
+	styles   []CommentStyle
+}
+
+func newLicenseHeader(address, template string, styles []CommentStyle) (licenseHeader, error) {
+	for _, s := range styles {
+		if s.LinePrefix == "" && (s.Start == "" || s.End == "") {
+			return licenseHeader{}, fmt.Errorf("%s: comment style for %s must set `line_prefix`, or both `start` and `end`", address, strings.Join(s.Extensions, ", "))
+		}
+	}
+	return licenseHeader{
+		template: template,
+		styles:   append(append([]CommentStyle{}, styles...), defaultCommentStyles...),
+	}, nil
+}
+
+// commentStyle returns the comment style of the file by its extension or its name, styles set in the config take precedence over the default ones.
+func (h licenseHeader) commentStyle(file string) (CommentStyle, error) {
+	ext := filepath.Ext(file)
+	base := filepath.Base(file)
+	for _, s := range h.styles {
+		for _, e := range s.Extensions {
+			if (ext != "" && strings.EqualFold(e, ext)) || e == base {
+				return s, nil
+			}
+		}
+	}
+	return CommentStyle{}, fmt.Errorf("no comment style for %s, please add a `comment_style` block", file)
+}
+
+// check returns a violation message if the file doesn't start with the license header.
+func (h licenseHeader) check(file string, content []byte) (string, error) {
+	style, err := h.commentStyle(file)
+	if err != nil {
+		return "", err
+	}
+	lines := splitTextLines(bytes.TrimPrefix(content, utf8BOM))
+	positions := headerPositions(file, lines)
+	for _, pos := range positions {
+		if h.matchAt(style, lines, pos) {
+			return "", nil
+		}
+	}
+	for _, pos := range positions {
+		if _, ok := existingHeader(style, lines, pos); ok {
+			return "license header is outdated", nil
+		}
+	}
+	return "missing license header", nil
+}
+
+// apply returns the content with the license header, an existing license header is replaced and its year is kept.
+// The header is inserted after shebang and encoding lines, for Go files with block comment style it's inserted after build constraints.
+func (h licenseHeader) apply(file string, content []byte, year string) ([]byte, error) {
+	style, err := h.commentStyle(file)
+	if err != nil {
+		return nil, err
+	}
+	bom := bytes.HasPrefix(content, utf8BOM)
+	lines := splitTextLines(bytes.TrimPrefix(content, utf8BOM))
+	positions := headerPositions(file, lines)
+	for _, pos := range positions {
+		if h.matchAt(style, lines, pos) {
+			return content, nil
+		}
+	}
+	insert := positions[0]
+	if style.Start != "" {
+		insert = positions[len(positions)-1]
+	}
+	replaceEnd := insert
+	for _, pos := range positions {
+		end, ok := existingHeader(style, lines, pos)
+		if !ok {
+			continue
+		}
+		insert, replaceEnd = pos, end
+		if y := yearRegex.Find(joinTextLines(lines[pos:end], "\n")); y != nil {
+			year = string(y)
+		}
+		break
+	}
+	eol := "\n"
+	if bytes.Contains(content, []byte("\r\n")) {
+		eol = "\r\n"
+	}
+	var buf bytes.Buffer
+	if bom {
+		buf.Write(utf8BOM)
+	}
+	buf.Write(joinTextLines(lines[:insert], eol))
+	if insert > 0 && lines[insert-1].eol == "" {
+		buf.WriteString(eol)
+	}
+	for _, line := range style.render(h.template, year) {
+		buf.WriteString(line)
+		buf.WriteString(eol)
+	}
+	if replaceEnd == insert && insert < len(lines) && len(bytes.TrimSpace(lines[insert].content)) > 0 {
+		buf.WriteString(eol)
+	}
+	buf.Write(joinTextLines(lines[replaceEnd:], eol))
+	return buf.Bytes(), nil
+}
+
+func (h licenseHeader) matchAt(style CommentStyle, lines []textLine, pos int) bool {
+	expected := style.render(h.template, yearPlaceholder)
+	if pos+len(expected) > len(lines) {
+		return false
+	}
+	for i, e := range expected {
+		pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(e), regexp.QuoteMeta(yearPlaceholder), yearRegex.String()) + "$"
+		matched, err := regexp.Match(pattern, bytes.TrimRight(lines[pos+i].content, " \t"))
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// headerPositions returns the line indexes where the license header could start, right after shebang and encoding lines,
+// and for Go files also after build constraints, so shebangs and build constraints are kept intact.
+func headerPositions(file string, lines []textLine) []int {
+	i := 0
+	if i < len(lines) && bytes.HasPrefix(lines[i].content, []byte("#!")) {
+		i++
+	}
+	if strings.EqualFold(filepath.Ext(file), ".py") && i < len(lines) && i < 2 && pythonEncodingRegex.Match(lines[i].content) {
+		i++
+	}
+	positions := []int{i}
+	if filepath.Ext(file) != ".go" {
+		return positions
+	}
+	j := i
+	for j < len(lines) && isBuildConstraint(lines[j].content) {
+		j++
+	}
+	if j == i {
+		return positions
+	}
+	for j < len(lines) && len(bytes.TrimSpace(lines[j].content)) == 0 {
+		j++
+	}
+	return append(positions, j)
+}
+
+func isBuildConstraint(line []byte) bool {
+	return bytes.HasPrefix(line, []byte("//go:build")) || bytes.HasPrefix(line, []byte("// +build"))
+}
+
+// existingHeader returns the end of the comment at pos if it looks like a license header, which mentions copyright or SPDX license identifier.
+func existingHeader(style CommentStyle, lines []textLine, pos int) (int, bool) {
+	end := pos
+	if start := strings.TrimSpace(style.Start); start != "" {
+		if pos < len(lines) && bytes.HasPrefix(bytes.TrimSpace(lines[pos].content), []byte(start)) {
+			stop := []byte(strings.TrimSpace(style.End))
+			for end < len(lines) {
+				line := lines[end].content
+				if end == pos {
+					line = bytes.TrimPrefix(bytes.TrimSpace(line), []byte(start))
+				}
+				end++
+				if bytes.Contains(line, stop) {
+					break
+				}
+			}
+		}
+	} else {
+		prefix := []byte(strings.TrimSpace(style.LinePrefix))
+		for end < len(lines) && bytes.HasPrefix(bytes.TrimLeft(lines[end].content, " \t"), prefix) && !isBuildConstraint(lines[end].content) {
+			end++
+		}
+	}
+	if end == pos {
+		return pos, false
+	}
+	comment := strings.ToLower(string(joinTextLines(lines[pos:end], "\n")))
+	return end, strings.Contains(comment, "copyright") || strings.Contains(comment, "spdx-license-identifier")
+}
+
+// joinTextLines joins the lines with their own line endings, missing line endings except the last one are replaced by eol.
+func joinTextLines(lines []textLine, eol string) []byte {
+	var buf bytes.Buffer
+	for i, line := range lines {
+		buf.Write(line.content)
+		switch {
+		case line.eol != "":
+			buf.WriteString(line.eol)
+		case i < len(lines)-1:
+			buf.WriteString(eol)
+		}
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,174 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/afero"
+)
+
+// Diff applies file based fixes in the plan to an in-memory copy of the paths they touch, and returns unified diffs of all changed files.
+// The real file system is not changed. Fixes that don't declare paths they touch, like `local_shell`, are skipped since their changes can't be previewed.
+func (p *GreptPlan) Diff() (string, error) {
+	if err := p.decodeFixes(); err != nil {
+		return "", err
+	}
+	var fixes []Fix
+	var skipped []string
+	if err := p.traverseFixes(func(fix Fix) error {
+		if _, ok := fix.(pathTouchingFix); !ok {
+			skipped = append(skipped, fix.Address())
+			return nil
+		}
+		fixes = append(fixes, fix)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	var touched, read []string
+	for _, fix := range fixes {
+		touched = append(touched, touchedPaths(fix)...)
+		if rf, ok := fix.(pathReadingFix); ok {
+			read = append(read, rf.readPaths()...)
+		}
+	}
+	fs := FsFactory()
+	before, err := newFsSnapshot(fs, append(read, touched...))
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = before.close()
+	}()
+	mem := afero.NewMemMapFs()
+	if err = before.restoreTo(mem); err != nil {
+		return "", err
+	}
+	if err = applyFixesOn(mem, fixes); err != nil {
+		return "", err
+	}
+	after, err := newFsSnapshot(mem, touched)
+	if err != nil {
+		return "", err
+	}
+	sb := strings.Builder{}
+	for _, address := range skipped {
+		fmt.Fprintf(&sb, "%s runs external commands, its changes can't be previewed\n", address)
+	}
+	beforeFiles, err := before.regularFiles()
+	if err != nil {
+		return "", err
+	}
+	afterFiles, err := after.regularFiles()
+	if err != nil {
+		return "", err
+	}
+	diff, err := unifiedDiff(beforeFiles, afterFiles, touched)
+	if err != nil {
+		return "", err
+	}
+	sb.WriteString(diff)
+	return sb.String(), nil
+}
+
+// applyFixesOn applies fixes to fs instead of the file system returned by FsFactory, no global state is changed, so it's safe to preview fixes while other fixes are applied.
+func applyFixesOn(fs afero.Fs, fixes []Fix) error {
+	for _, fix := range fixes {
+		fix.setTargetFs(fs)
+	}
+	defer func() {
+		for _, fix := range fixes {
+			fix.setTargetFs(nil)
+		}
+	}()
+	for _, fix := range fixes {
+		if err := fix.Apply(); err != nil {
+			return fmt.Errorf("error previewing %s: %+v", fix.Address(), err)
+		}
+	}
+	return nil
+}
+
+// unifiedDiff returns diffs of files under touched paths, files that only appear in before are deleted, files that only appear in after are created.
+func unifiedDiff(before, after map[string][]byte, touched []string) (string, error) {
+	names := make(map[string]struct{})
+	for _, files := range []map[string][]byte{before, after} {
+		for name := range files {
+			if underAny(name, touched) {
+				names[name] = struct{}{}
+			}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	sb := strings.Builder{}
+	for _, name := range sorted {
+		a, inBefore := before[name]
+		b, inAfter := after[name]
+		if inBefore && inAfter && bytes.Equal(a, b) {
+			continue
+		}
+		slashName := filepath.ToSlash(name)
+		fromFile, toFile := "a/"+strings.TrimPrefix(slashName, "/"), "b/"+strings.TrimPrefix(slashName, "/")
+		if !inBefore {
+			fromFile = "/dev/null"
+		}
+		if !inAfter {
+			toFile = "/dev/null"
+		}
+		if isBinary(a) || isBinary(b) {
+			fmt.Fprintf(&sb, "Binary files %s and %s differ\n", fromFile, toFile)
+			continue
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(string(a)),
+			B:        splitLines(string(b)),
+			FromFile: fromFile,
+			ToFile:   toFile,
+			Context:  3,
+		})
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(diff)
+	}
+	return sb.String(), nil
+}
+
+// splitLines splits content into lines that end with "\n", difflib.SplitLines would return an extra empty line for content ends with "\n".
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+	return lines
+}
+
+func underAny(name string, paths []string) bool {
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		if p == "." || name == p || strings.HasPrefix(name, strings.TrimSuffix(p, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
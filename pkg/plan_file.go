@@ -0,0 +1,381 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/spf13/afero"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const planFileVersion = 1
+
+// absentPathHash is the input hash of a path that doesn't exist.
+const absentPathHash = "absent"
+
+// planFile is the content of a saved plan, fixes are saved with their decoded attributes in the order they should be applied,
+// so applying a saved plan doesn't evaluate the config again.
+type planFile struct {
+	Version     int               `json:"version"`
+	ConfigDir   string            `json:"config_dir"`
+	ConfigHash  string            `json:"config_hash"`
+	InputHashes map[string]string `json:"input_hashes"`
+	// RuleInputs are files that failed rules read during plan by rule address, so rule checks that the plan is based on could be checked for drift too.
+	RuleInputs  map[string][]ruleInput `json:"rule_inputs"`
+	FailedRules []jsonFailedRule       `json:"failed_rules"`
+	Fixes       []jsonFix              `json:"fixes"`
+	// FixDependencies are addresses of fixes in the plan that a fix depends on, by fix address, so a loaded plan keeps the order between them under parallel apply.
+	FixDependencies map[string][]string `json:"fix_dependencies"`
+}
+
+// ruleInput is a glob that a rule used to find files it read, the hash covers matched paths and content of matched files,
+// so both changed files and newly matched files are drift.
+type ruleInput struct {
+	Glob             string   `json:"glob"`
+	Excludes         []string `json:"excludes,omitempty"`
+	RespectGitIgnore bool     `json:"respect_gitignore,omitempty"`
+	CaseInsensitive  bool     `json:"case_insensitive,omitempty"`
+	Hash             string   `json:"hash"`
+}
+
+func (ri ruleInput) walker() *fileWalker {
+	w := newFileWalker(ri.Glob, ri.Excludes, ri.RespectGitIgnore)
+	w.caseInsensitive = ri.CaseInsensitive
+	return w
+}
+
+// Save writes the plan to a file, which could be applied later by LoadPlan and Apply.
+func (p *GreptPlan) Save(planPath string) error {
+	if err := p.decodeFixes(); err != nil {
+		return err
+	}
+	pf := planFile{
+		Version:         planFileVersion,
+		InputHashes:     make(map[string]string),
+		RuleInputs:      make(map[string][]ruleInput),
+		FailedRules:     []jsonFailedRule{},
+		Fixes:           []jsonFix{},
+		FixDependencies: make(map[string][]string),
+	}
+	fs := FsFactory()
+	var err error
+	if p.c != nil {
+		pf.ConfigDir = p.c.cfgDir
+		if pf.ConfigHash, err = configHash(fs, p.c.cfgDir); err != nil {
+			return err
+		}
+	}
+	for _, fr := range p.sortedFailedRules() {
+		pf.FailedRules = append(pf.FailedRules, newJsonFailedRule(fr))
+		inputs, err := newRuleInputs(fs, fr.Rule, planPath)
+		if err != nil {
+			return err
+		}
+		if len(inputs) > 0 {
+			pf.RuleInputs[fr.Address()] = inputs
+		}
+	}
+	ruleAddresses := p.failedRuleAddresses()
+	var saved []Fix
+	if err = p.traverseFixes(func(fix Fix) error {
+		ancestors := p.ancestors(fix)
+		for _, s := range saved {
+			if ancestors[s.Address()] {
+				pf.FixDependencies[fix.Address()] = append(pf.FixDependencies[fix.Address()], s.Address())
+			}
+		}
+		saved = append(saved, fix)
+		pf.Fixes = append(pf.Fixes, newJsonFix(fix, ruleAddresses))
+		for _, path := range fixInputPaths(fix) {
+			if pf.InputHashes[path], err = pathHash(fs, path, planPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(pf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling plan: %+v", err)
+	}
+	return afero.WriteFile(fs, planPath, content, 0644)
+}
+
+// LoadPlan reads a plan saved by Save, it returns an error if the config or any file that fixes would touch has changed since the plan was saved.
+func LoadPlan(planPath string) (*GreptPlan, error) {
+	fs := FsFactory()
+	content, err := afero.ReadFile(fs, planPath)
+	if err != nil {
+		return nil, err
+	}
+	var pf planFile
+	if err = json.Unmarshal(content, &pf); err != nil {
+		return nil, fmt.Errorf("invalid plan file %s: %+v", planPath, err)
+	}
+	if pf.Version != planFileVersion {
+		return nil, fmt.Errorf("unsupported plan file version %d, expected %d", pf.Version, planFileVersion)
+	}
+	if err = pf.checkDrift(fs, planPath); err != nil {
+		return nil, err
+	}
+	p := newPlan(nil)
+	ruleIds := make(map[string]string)
+	for _, r := range pf.FailedRules {
+		attributes, err := json.Marshal(&BaseRule{
+			Severity:       r.Severity,
+			Description:    r.Description,
+			RemediationUrl: r.RemediationUrl,
+			Tags:           r.Tags,
+		})
+		if err != nil {
+			return nil, err
+		}
+		b, err := newBlockFromAddress(r.Address, attributes)
+		if err != nil {
+			return nil, err
+		}
+		rule := b.(Rule)
+		rule.setCheckError(errors.New(r.Error))
+		ruleIds[r.Address] = rule.Id()
+		p.addRule(&FailedRule{
+			Rule:       rule,
+			CheckError: rule.CheckError(),
+		})
+	}
+	for _, f := range pf.Fixes {
+		b, err := newBlockFromAddress(f.Address, f.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		fix := b.(Fix)
+		var ids []string
+		for _, address := range f.Rules {
+			ids = append(ids, ruleIds[address])
+		}
+		fix.setRuleIds(ids)
+		p.addFix(fix)
+		p.savedFixes = append(p.savedFixes, fix)
+	}
+	p.savedDependencies = make(map[string]map[string]bool)
+	for address, dependencies := range pf.FixDependencies {
+		p.savedDependencies[address] = make(map[string]bool)
+		for _, d := range dependencies {
+			p.savedDependencies[address][d] = true
+		}
+	}
+	return p, nil
+}
+
+func (pf planFile) checkDrift(fs afero.Fs, planPath string) error {
+	var drifted []string
+	if pf.ConfigHash != "" {
+		// config downloaded from a remote source has been removed after plan, so it can only be checked when it's local
+		if exist, _ := afero.DirExists(fs, pf.ConfigDir); exist {
+			hash, err := configHash(fs, pf.ConfigDir)
+			if err != nil {
+				return err
+			}
+			if hash != pf.ConfigHash {
+				drifted = append(drifted, fmt.Sprintf("config %s", pf.ConfigDir))
+			}
+		}
+	}
+	paths := make([]string, 0, len(pf.InputHashes))
+	for path := range pf.InputHashes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		hash, err := pathHash(fs, path, planPath)
+		if err != nil {
+			return err
+		}
+		if hash != pf.InputHashes[path] {
+			drifted = append(drifted, path)
+		}
+	}
+	addresses := make([]string, 0, len(pf.RuleInputs))
+	for address := range pf.RuleInputs {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		for _, input := range pf.RuleInputs[address] {
+			hash, err := globHash(fs, input.walker(), planPath)
+			if err != nil {
+				return err
+			}
+			if hash != input.Hash {
+				drifted = append(drifted, fmt.Sprintf("%s read by %s", input.Glob, address))
+			}
+		}
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("the working tree has drifted since the plan was saved, please run plan again. changed: %s", strings.Join(drifted, ", "))
+	}
+	return nil
+}
+
+// newRuleInputs returns inputs that the rule recorded during plan, duplicated globs are recorded once.
+func newRuleInputs(fs afero.Fs, rule Rule, planPath string) ([]ruleInput, error) {
+	var inputs []ruleInput
+	seen := make(map[string]bool)
+	for _, w := range rule.inputWalkers() {
+		input := ruleInput{
+			Glob:             w.glob,
+			Excludes:         w.excludes,
+			RespectGitIgnore: w.respectGitIgnore,
+			CaseInsensitive:  w.caseInsensitive,
+		}
+		key := fmt.Sprintf("%s\x00%s\x00%t\x00%t", input.Glob, strings.Join(input.Excludes, "\x00"), input.RespectGitIgnore, input.CaseInsensitive)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		var err error
+		if input.Hash, err = globHash(fs, w, planPath); err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// globHash returns the hash of paths matched by the walker and content of matched files, the plan file itself is excluded since it might be matched.
+func globHash(fs afero.Fs, w *fileWalker, planPath string) (string, error) {
+	matches, err := w.Glob(fs)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, name := range matches {
+		if filepath.Clean(name) == filepath.Clean(planPath) {
+			continue
+		}
+		info, err := lstatIfPossible(fs, filepath.FromSlash(name))
+		if err != nil {
+			return "", err
+		}
+		var content []byte
+		if info.Mode().IsRegular() {
+			if content, err = afero.ReadFile(fs, filepath.FromSlash(name)); err != nil {
+				return "", err
+			}
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00%s\x00%d\x00", name, info.Mode(), len(content))
+		_, _ = h.Write(content)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+func fixInputPaths(fix Fix) []string {
+	paths := touchedPaths(fix)
+	if rf, ok := fix.(pathReadingFix); ok {
+		paths = append(paths, rf.readPaths()...)
+	}
+	return paths
+}
+
+// configHash returns the hash of all config files in the folder.
+func configHash(fs afero.Fs, dir string) (string, error) {
+	matches, err := afero.Glob(fs, filepath.Join(dir, "*.grept.hcl"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(matches)
+	h := sha256.New()
+	for _, name := range matches {
+		content, err := afero.ReadFile(fs, name)
+		if err != nil {
+			return "", err
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00", filepath.Base(name), len(content))
+		_, _ = h.Write(content)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// pathHash returns the hash of a file, or all files and folders under a folder, the plan file itself is excluded since it might be under the folder.
+func pathHash(fs afero.Fs, path, planPath string) (string, error) {
+	s, err := newFsSnapshot(fs, []string{path})
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = s.close()
+	}()
+	root := s.roots[0]
+	if !root.exist {
+		return absentPathHash, nil
+	}
+	names := make([]string, 0, len(root.entries))
+	for name := range root.entries {
+		if name != filepath.Clean(planPath) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		entry := root.entries[name]
+		var content []byte
+		if !entry.isDir && !entry.isLink() {
+			if content, err = s.read(entry); err != nil {
+				return "", err
+			}
+		}
+		_, _ = fmt.Fprintf(h, "%s\x00%t\x00%o\x00%s\x00%d\x00", filepath.ToSlash(name), entry.isDir, entry.mode, entry.link, len(content))
+		_, _ = h.Write(content)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// newBlockFromAddress creates a block without config by its address like `fix.local_file.license[key]`, attributes would be unmarshalled into the block if it's not empty.
+func newBlockFromAddress(address string, attributes json.RawMessage) (golden.Block, error) {
+	blockAddress, key, hasKey := strings.Cut(address, "[")
+	segments := strings.Split(blockAddress, ".")
+	if len(segments) != 3 {
+		return nil, fmt.Errorf("invalid block address %s", address)
+	}
+	blockType, t, name := segments[0], segments[1], segments[2]
+	prototype, ok := blockPrototypes[blockType+"."+t]
+	if !ok {
+		return nil, fmt.Errorf("unknown block type %s %s in %s", blockType, t, address)
+	}
+	var forEach *golden.ForEach
+	if hasKey {
+		key = strings.TrimSuffix(key, "]")
+		forEach = golden.NewForEach(cty.StringVal(key), cty.StringVal(key))
+	}
+	labels := []string{t, name}
+	hb := golden.NewHclBlock(&hclsyntax.Block{
+		Type:   blockType,
+		Labels: labels,
+		Body:   &hclsyntax.Body{},
+	}, hclwrite.NewBlock(blockType, labels), forEach)
+	v := reflect.New(reflect.TypeOf(prototype).Elem())
+	elem := v.Elem()
+	elem.FieldByName("BaseBlock").Set(reflect.ValueOf(golden.NewBaseBlock(nil, hb)))
+	for _, base := range []string{"BaseRule", "BaseFix", "BaseData"} {
+		if f := elem.FieldByName(base); f.IsValid() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+	}
+	b := v.Interface().(golden.Block)
+	if len(attributes) > 0 {
+		if err := json.Unmarshal(attributes, b); err != nil {
+			return nil, fmt.Errorf("invalid attributes of %s: %+v", address, err)
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,48 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Graph returns fixes in the plan as a DOT graph, nodes are labeled with the order they would be applied in,
+// an edge from a fix to another means the latter depends on the former, by `depends_on` or by referencing it.
+func (p *GreptPlan) Graph() (string, error) {
+	var fixes []Fix
+	if err := p.traverseFixes(func(fix Fix) error {
+		fixes = append(fixes, fix)
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	ancestors := make([]map[string]bool, len(fixes))
+	for i, fix := range fixes {
+		ancestors[i] = p.ancestors(fix)
+	}
+	sb := strings.Builder{}
+	sb.WriteString("digraph {\n")
+	sb.WriteString("  rankdir = \"LR\"\n")
+	for i, fix := range fixes {
+		fmt.Fprintf(&sb, "  %q [label=%q]\n", fix.Address(), fmt.Sprintf("%d: %s", i+1, fix.Address()))
+	}
+	for j, fix := range fixes {
+		for i := 0; i < j; i++ {
+			if !ancestors[j][fixes[i].Address()] || indirectDependency(fixes, ancestors, i, j) {
+				continue
+			}
+			fmt.Fprintf(&sb, "  %q -> %q\n", fixes[i].Address(), fix.Address())
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// indirectDependency returns true if fixes[j] depends on fixes[i] through another fix, the edge between them is omitted to keep the graph readable.
+func indirectDependency(fixes []Fix, ancestors []map[string]bool, i, j int) bool {
+	for k := i + 1; k < j; k++ {
+		if ancestors[k][fixes[i].Address()] && ancestors[j][fixes[k].Address()] {
+			return true
+		}
+	}
+	return false
+}
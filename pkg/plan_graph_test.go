@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *greptConfigSuite) TestPlan_Graph() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "rename_file" config {
+		rule_ids = [rule.must_be_true.sample.id]
+		old_name = "/config.yml"
+		new_name = "/config.yaml"
+	}
+
+	fix "yaml_transform" config {
+		rule_ids   = [rule.must_be_true.sample.id]
+		file_path  = "/config.yaml"
+		depends_on = [fix.rename_file.config]
+		transform {
+			yaml_path = "/name"
+			string_value = "grept"
+		}
+	}
+
+	fix "local_file" readme {
+		rule_ids   = [rule.must_be_true.sample.id]
+		paths      = ["/README.md"]
+		content    = "readme"
+		depends_on = [fix.yaml_transform.config, fix.rename_file.config]
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl", "/config.yml"}, []string{content, "name: old\n"})
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+
+	graph, err := plan.Graph()
+	require.NoError(t, err)
+	s.Equal(`digraph {
+  rankdir = "LR"
+  "fix.rename_file.config" [label="1: fix.rename_file.config"]
+  "fix.yaml_transform.config" [label="2: fix.yaml_transform.config"]
+  "fix.local_file.readme" [label="3: fix.local_file.readme"]
+  "fix.rename_file.config" -> "fix.yaml_transform.config"
+  "fix.yaml_transform.config" -> "fix.local_file.readme"
+}
+`, graph)
+
+	require.NoError(t, plan.Apply())
+	exist, err := afero.Exists(s.fs, "/config.yml")
+	require.NoError(t, err)
+	s.False(exist)
+	transformed, err := afero.ReadFile(s.fs, "/config.yaml")
+	require.NoError(t, err)
+	s.Contains(string(transformed), "grept")
+}
@@ -0,0 +1,482 @@
+package pkg
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/hcl/v2"
+)
+
+const (
+	OutputFormatText  = "text"
+	OutputFormatJson  = "json"
+	OutputFormatSarif = "sarif"
+	OutputFormatJUnit = "junit"
+)
+
+var OutputFormats = []string{OutputFormatText, OutputFormatJson, OutputFormatSarif, OutputFormatJUnit}
+
+// Render renders the plan in the given output format, `text` renders the same content as String.
+func (p *GreptPlan) Render(format string) (string, error) {
+	var r []byte
+	var err error
+	switch format {
+	case "", OutputFormatText:
+		return p.String(), nil
+	case OutputFormatJson:
+		r, err = json.MarshalIndent(p.jsonReport(), "", "  ")
+	case OutputFormatSarif:
+		r, err = json.MarshalIndent(p.sarifReport(), "", "  ")
+	case OutputFormatJUnit:
+		r, err = xml.MarshalIndent(p.junitReport(), "", "  ")
+		r = append([]byte(xml.Header), r...)
+	default:
+		return "", fmt.Errorf("unsupported output format %s, valid formats are: %s", format, strings.Join(OutputFormats, ", "))
+	}
+	if err != nil {
+		return "", fmt.Errorf("error rendering plan as %s: %+v", format, err)
+	}
+	return string(r), nil
+}
+
+type jsonPlanReport struct {
+	FailedRules     []jsonFailedRule     `json:"failed_rules"`
+	SuppressedRules []jsonSuppressedRule `json:"suppressed_rules"`
+	Fixes           []jsonFix            `json:"fixes"`
+}
+
+type jsonFailedRule struct {
+	Address        string     `json:"address"`
+	Type           string     `json:"type"`
+	Name           string     `json:"name"`
+	Severity       string     `json:"severity"`
+	Description    string     `json:"description,omitempty"`
+	RemediationUrl string     `json:"remediation_url,omitempty"`
+	Tags           []string   `json:"tags"`
+	Error          string     `json:"error"`
+	Range          *jsonRange `json:"range,omitempty"`
+}
+
+type jsonSuppressedRule struct {
+	jsonFailedRule
+	Reason       string     `json:"reason"`
+	ExpiresOn    string     `json:"expires_on,omitempty"`
+	SuppressedBy *jsonRange `json:"suppressed_by,omitempty"`
+}
+
+type jsonFix struct {
+	Address    string          `json:"address"`
+	Type       string          `json:"type"`
+	Name       string          `json:"name"`
+	Rules      []string        `json:"rules"`
+	Range      *jsonRange      `json:"range,omitempty"`
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+type jsonRange struct {
+	Filename string  `json:"filename"`
+	Start    jsonPos `json:"start"`
+	End      jsonPos `json:"end"`
+}
+
+type jsonPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p *GreptPlan) jsonReport() jsonPlanReport {
+	r := jsonPlanReport{
+		FailedRules:     []jsonFailedRule{},
+		SuppressedRules: []jsonSuppressedRule{},
+		Fixes:           []jsonFix{},
+	}
+	for _, fr := range p.sortedFailedRules() {
+		r.FailedRules = append(r.FailedRules, newJsonFailedRule(fr))
+	}
+	for _, sr := range p.sortedSuppressedRules() {
+		r.SuppressedRules = append(r.SuppressedRules, jsonSuppressedRule{
+			jsonFailedRule: newJsonFailedRule(sr.FailedRule),
+			Reason:         sr.Suppression.Reason,
+			ExpiresOn:      sr.Suppression.ExpiresOn,
+			SuppressedBy:   newJsonRange(sr.Suppression.Range),
+		})
+	}
+	ruleAddresses := p.failedRuleAddresses()
+	for _, f := range p.sortedFixes() {
+		r.Fixes = append(r.Fixes, newJsonFix(f, ruleAddresses))
+	}
+	return r
+}
+
+// failedRuleAddresses returns a map from failed rule's id to its address.
+func (p *GreptPlan) failedRuleAddresses() map[string]string {
+	ruleAddresses := make(map[string]string)
+	for _, fr := range p.FailedRules {
+		ruleAddresses[fr.Id()] = fr.Address()
+	}
+	return ruleAddresses
+}
+
+func newJsonFailedRule(fr *FailedRule) jsonFailedRule {
+	tags := fr.GetTags()
+	if tags == nil {
+		tags = []string{}
+	}
+	return jsonFailedRule{
+		Address:        fr.Address(),
+		Type:           fr.Type(),
+		Name:           fr.Name(),
+		Severity:       fr.GetSeverity(),
+		Description:    fr.GetDescription(),
+		RemediationUrl: fr.GetRemediationUrl(),
+		Tags:           tags,
+		Error:          fr.CheckError.Error(),
+		Range:          newJsonRange(fr.HclBlock().Range()),
+	}
+}
+
+func newJsonFix(f Fix, ruleAddresses map[string]string) jsonFix {
+	attributes, err := json.Marshal(f)
+	if err != nil {
+		attributes, _ = json.Marshal(err.Error())
+	}
+	rules := []string{}
+	for _, id := range f.GetRuleIds() {
+		if address, ok := ruleAddresses[id]; ok {
+			rules = append(rules, address)
+		}
+	}
+	return jsonFix{
+		Address:    f.Address(),
+		Type:       f.Type(),
+		Name:       f.Name(),
+		Rules:      rules,
+		Range:      newJsonRange(f.HclBlock().Range()),
+		Attributes: attributes,
+	}
+}
+
+func newJsonRange(r hcl.Range) *jsonRange {
+	if r.Filename == "" {
+		return nil
+	}
+	return &jsonRange{
+		Filename: r.Filename,
+		Start:    jsonPos{Line: r.Start.Line, Column: r.Start.Column},
+		End:      jsonPos{Line: r.End.Line, Column: r.End.Column},
+	}
+}
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string           `json:"id"`
+	Name             string           `json:"name"`
+	ShortDescription sarifMessage     `json:"shortDescription"`
+	FullDescription  *sarifMessage    `json:"fullDescription,omitempty"`
+	HelpUri          string           `json:"helpUri,omitempty"`
+	Properties       *sarifProperties `json:"properties,omitempty"`
+}
+
+type sarifProperties struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type sarifResult struct {
+	RuleId       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+func (p *GreptPlan) sarifReport() sarifReport {
+	driver := sarifDriver{
+		Name:           "grept",
+		InformationUri: "https://github.com/Azure/grept",
+		Rules:          []sarifRule{},
+	}
+	results := []sarifResult{}
+	var suppressions []*Suppression
+	var rules []*FailedRule
+	for _, fr := range p.sortedFailedRules() {
+		rules = append(rules, fr)
+		suppressions = append(suppressions, nil)
+	}
+	for _, sr := range p.sortedSuppressedRules() {
+		rules = append(rules, sr.FailedRule)
+		suppressions = append(suppressions, sr.Suppression)
+	}
+	for i, fr := range rules {
+		rule := sarifRule{
+			Id:               fr.Address(),
+			Name:             fr.Type(),
+			ShortDescription: sarifMessage{Text: fmt.Sprintf("%s rule %s", fr.Type(), fr.Name())},
+			HelpUri:          fr.GetRemediationUrl(),
+		}
+		if description := fr.GetDescription(); description != "" {
+			rule.FullDescription = &sarifMessage{Text: description}
+		}
+		if tags := fr.GetTags(); len(tags) > 0 {
+			rule.Properties = &sarifProperties{Tags: tags}
+		}
+		driver.Rules = append(driver.Rules, rule)
+		result := sarifResult{
+			RuleId:    fr.Address(),
+			RuleIndex: i,
+			Level:     sarifLevel(fr.GetSeverity()),
+			Message:   sarifMessage{Text: fr.CheckError.Error()},
+		}
+		if location := newSarifLocation(fr.HclBlock().Range()); location != nil {
+			result.Locations = []sarifLocation{*location}
+		}
+		if s := suppressions[i]; s != nil {
+			// code scanning tools hide results with suppressions
+			result.Suppressions = []sarifSuppression{
+				{
+					Kind:          "external",
+					Justification: s.Reason,
+				},
+			}
+		}
+		results = append(results, result)
+	}
+	return sarifReport{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool:    sarifTool{Driver: driver},
+				Results: results,
+			},
+		},
+	}
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+func newSarifLocation(r hcl.Range) *sarifLocation {
+	if r.Filename == "" {
+		return nil
+	}
+	location := &sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{Uri: strings.ReplaceAll(r.Filename, "\\", "/")},
+		},
+	}
+	if r.Start.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   r.Start.Line,
+			StartColumn: r.Start.Column,
+			EndLine:     r.End.Line,
+			EndColumn:   r.End.Column,
+		}
+	}
+	return location
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// junitReport reports every rule in the config as a test case, so passed rules are visible in test reporters too.
+func (p *GreptPlan) junitReport() junitTestSuites {
+	failed := make(map[string]*FailedRule)
+	for _, fr := range p.FailedRules {
+		failed[fr.Address()] = fr
+	}
+	suppressed := make(map[string]*SuppressedRule)
+	for _, sr := range p.SuppressedRules {
+		suppressed[sr.Address()] = sr
+	}
+	baselined := make(map[string]*FailedRule)
+	for _, br := range p.BaselinedRules {
+		baselined[br.Address()] = br
+	}
+	var rules []Rule
+	if p.c != nil {
+		for _, r := range golden.Blocks[Rule](p.c) {
+			// rules that are not selected by targets but evaluated as dependencies are not reported
+			if selected, _ := p.c.selection.ruleSelected(r); selected {
+				rules = append(rules, r)
+			}
+		}
+	} else {
+		for _, fr := range p.FailedRules {
+			rules = append(rules, fr.Rule)
+		}
+		for _, sr := range p.SuppressedRules {
+			rules = append(rules, sr.Rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Address() < rules[j].Address()
+	})
+	suite := junitTestSuite{
+		Name:      "grept",
+		TestCases: []junitTestCase{},
+	}
+	for _, r := range rules {
+		rg := r.HclBlock().Range()
+		tc := junitTestCase{
+			Name:      r.Address(),
+			ClassName: fmt.Sprintf("%s.%s", r.BlockType(), r.Type()),
+			File:      rg.Filename,
+			Line:      rg.Start.Line,
+		}
+		if fr, ok := failed[r.Address()]; ok {
+			tc.Failure = &junitFailure{
+				Message: fr.CheckError.Error(),
+				Type:    fr.GetSeverity(),
+				Content: fr.String(),
+			}
+			suite.Failures++
+		}
+		// suppressed failures are reported as skipped test cases
+		if sr, ok := suppressed[r.Address()]; ok {
+			tc.Skipped = &junitSkipped{
+				Message: sr.String(),
+			}
+		}
+		if br, ok := baselined[r.Address()]; ok {
+			tc.Skipped = &junitSkipped{
+				Message: fmt.Sprintf("%s, known in baseline", br.String()),
+			}
+		}
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	return junitTestSuites{
+		Name:     "grept",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+}
+
+func (p *GreptPlan) sortedFailedRules() []*FailedRule {
+	rules := make([]*FailedRule, len(p.FailedRules))
+	copy(rules, p.FailedRules)
+	sort.Slice(rules, func(i, j int) bool {
+		ri, rj := severityRank(rules[i].GetSeverity()), severityRank(rules[j].GetSeverity())
+		if ri != rj {
+			return ri < rj
+		}
+		return rules[i].Address() < rules[j].Address()
+	})
+	return rules
+}
+
+func (p *GreptPlan) sortedSuppressedRules() []*SuppressedRule {
+	rules := make([]*SuppressedRule, len(p.SuppressedRules))
+	copy(rules, p.SuppressedRules)
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Address() < rules[j].Address()
+	})
+	return rules
+}
+
+func (p *GreptPlan) sortedFixes() []Fix {
+	var fixes []Fix
+	for _, f := range p.Fixes {
+		fixes = append(fixes, f)
+	}
+	sort.Slice(fixes, func(i, j int) bool {
+		return fixes[i].Address() < fixes[j].Address()
+	})
+	return fixes
+}
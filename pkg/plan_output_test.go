@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPlanForOutput() *GreptPlan {
+	rule := &FileHashRule{
+		BaseBlock: golden.NewBaseBlock(nil, golden.NewHclBlock(&hclsyntax.Block{
+			Type:   "rule",
+			Labels: []string{"file_hash", "license"},
+			Body:   &hclsyntax.Body{},
+			TypeRange: hcl.Range{
+				Filename: "main.grept.hcl",
+				Start:    hcl.Pos{Line: 3, Column: 1},
+				End:      hcl.Pos{Line: 3, Column: 5},
+			},
+			CloseBraceRange: hcl.Range{
+				Filename: "main.grept.hcl",
+				Start:    hcl.Pos{Line: 6, Column: 1},
+				End:      hcl.Pos{Line: 6, Column: 2},
+			},
+		}, hclwrite.NewBlock("rule", []string{"file_hash", "license"}), nil)),
+		BaseRule: new(BaseRule),
+		Glob:     "LICENSE",
+		Hash:     "abc",
+	}
+	fix := &LocalFileFix{
+		BaseBlock: golden.NewBaseBlock(nil, golden.NewHclBlock(&hclsyntax.Block{
+			Type:   "fix",
+			Labels: []string{"local_file", "license"},
+			Body:   &hclsyntax.Body{},
+		}, hclwrite.NewBlock("fix", []string{"local_file", "license"}), nil)),
+		BaseFix: &BaseFix{RuleIds: []string{rule.Id()}},
+		Paths:   []string{"LICENSE"},
+		Content: "MIT",
+	}
+	return &GreptPlan{
+		FailedRules: []*FailedRule{
+			{
+				Rule:       rule,
+				CheckError: fmt.Errorf("hash mismatch"),
+			},
+		},
+		Fixes: map[string]Fix{
+			fix.Id(): fix,
+		},
+	}
+}
+
+func TestPlan_RenderJson(t *testing.T) {
+	out, err := testPlanForOutput().Render(OutputFormatJson)
+	require.NoError(t, err)
+	var report jsonPlanReport
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	require.Len(t, report.FailedRules, 1)
+	assert.Equal(t, "rule.file_hash.license", report.FailedRules[0].Address)
+	assert.Equal(t, "hash mismatch", report.FailedRules[0].Error)
+	require.NotNil(t, report.FailedRules[0].Range)
+	assert.Equal(t, "main.grept.hcl", report.FailedRules[0].Range.Filename)
+	assert.Equal(t, 3, report.FailedRules[0].Range.Start.Line)
+	require.Len(t, report.Fixes, 1)
+	assert.Equal(t, "fix.local_file.license", report.Fixes[0].Address)
+	assert.Equal(t, []string{"rule.file_hash.license"}, report.Fixes[0].Rules)
+	var attributes map[string]any
+	require.NoError(t, json.Unmarshal(report.Fixes[0].Attributes, &attributes))
+	assert.Equal(t, "MIT", attributes["content"])
+}
+
+func TestPlan_RenderSarif(t *testing.T) {
+	out, err := testPlanForOutput().Render(OutputFormatSarif)
+	require.NoError(t, err)
+	var report sarifReport
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.Equal(t, "2.1.0", report.Version)
+	require.Len(t, report.Runs, 1)
+	run := report.Runs[0]
+	require.Len(t, run.Tool.Driver.Rules, 1)
+	assert.Equal(t, "rule.file_hash.license", run.Tool.Driver.Rules[0].Id)
+	require.Len(t, run.Results, 1)
+	result := run.Results[0]
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, "hash mismatch", result.Message.Text)
+	require.Len(t, result.Locations, 1)
+	assert.Equal(t, "main.grept.hcl", result.Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+	assert.Equal(t, 3, result.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, 6, result.Locations[0].PhysicalLocation.Region.EndLine)
+}
+
+func TestPlan_RenderJUnit(t *testing.T) {
+	out, err := testPlanForOutput().Render(OutputFormatJUnit)
+	require.NoError(t, err)
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal([]byte(out), &report))
+	assert.Equal(t, 1, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	require.Len(t, report.Suites, 1)
+	require.Len(t, report.Suites[0].TestCases, 1)
+	tc := report.Suites[0].TestCases[0]
+	assert.Equal(t, "rule.file_hash.license", tc.Name)
+	assert.Equal(t, "rule.file_hash", tc.ClassName)
+	require.NotNil(t, tc.Failure)
+	assert.Equal(t, "hash mismatch", tc.Failure.Message)
+}
+
+func TestPlan_RenderUnsupportedFormat(t *testing.T) {
+	_, err := testPlanForOutput().Render("yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported output format")
+}
+
+func (s *greptConfigSuite) TestPlan_RenderJUnitContainsPassedRules() {
+	t := s.T()
+	content := `
+	rule "must_be_true" pass {
+		condition = true
+	}
+
+	rule "must_be_true" fail {
+		condition = false
+		error_message = "failed"
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+
+	out, err := plan.Render(OutputFormatJUnit)
+	require.NoError(t, err)
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal([]byte(out), &report))
+	assert.Equal(t, 2, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	cases := report.Suites[0].TestCases
+	require.Len(t, cases, 2)
+	assert.Equal(t, "rule.must_be_true.fail", cases[0].Name)
+	assert.NotNil(t, cases[0].Failure)
+	assert.Equal(t, "rule.must_be_true.pass", cases[1].Name)
+	assert.Nil(t, cases[1].Failure)
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// applyFixes calls fn with fixes in the plan, with Parallelism greater than 1, fixes that don't conflict run at the same time.
+// A fix starts after all conflicting fixes before it in dependency order are done, so conflicting fixes are applied in the same order as sequential apply.
+func (p *GreptPlan) applyFixes(fn func(fix Fix) error) error {
+	if p.Parallelism <= 1 {
+		return p.traverseFixes(fn)
+	}
+	var fixes []Fix
+	if err := p.traverseFixes(func(fix Fix) error {
+		fixes = append(fixes, fix)
+		return nil
+	}); err != nil {
+		return err
+	}
+	waitFor := make([][]int, len(fixes))
+	for j, fix := range fixes {
+		ancestors := p.ancestors(fix)
+		for i := 0; i < j; i++ {
+			if ancestors[fixes[i].Address()] || fixesConflict(fixes[i], fix) {
+				waitFor[j] = append(waitFor[j], i)
+			}
+		}
+	}
+	done := make([]chan struct{}, len(fixes))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	errs := make([]error, len(fixes))
+	semaphore := make(chan struct{}, p.Parallelism)
+	wg := sync.WaitGroup{}
+	for j := range fixes {
+		wg.Add(1)
+		go func(j int) {
+			defer wg.Done()
+			defer close(done[j])
+			for _, i := range waitFor[j] {
+				<-done[i]
+			}
+			semaphore <- struct{}{}
+			defer func() {
+				<-semaphore
+			}()
+			errs[j] = fn(fixes[j])
+		}(j)
+	}
+	wg.Wait()
+	var err error
+	for _, fixErr := range errs {
+		if fixErr != nil {
+			err = multierror.Append(err, fixErr)
+		}
+	}
+	return err
+}
+
+// ancestors returns addresses of all blocks that the fix depends on, for fixes loaded from a saved plan, only fixes in the plan are returned.
+func (p *GreptPlan) ancestors(fix Fix) map[string]bool {
+	r := make(map[string]bool)
+	if p.c == nil {
+		for address := range p.savedDependencies[fix.Address()] {
+			r[address] = true
+		}
+		return r
+	}
+	ancestors, err := p.c.GetAncestors(fix.Address())
+	if err != nil {
+		return r
+	}
+	for address := range ancestors {
+		r[address] = true
+	}
+	return r
+}
+
+// fixesConflict returns true if a fix touches paths that the other one touches or reads.
+func fixesConflict(a, b Fix) bool {
+	aTouched, bTouched := touchedPaths(a), touchedPaths(b)
+	return pathsOverlap(aTouched, fixInputPaths(b)) || pathsOverlap(bTouched, fixInputPaths(a))
+}
+
+func pathsOverlap(paths, others []string) bool {
+	for _, path := range paths {
+		for _, other := range others {
+			if pathContains(path, other) || pathContains(other, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pathContains returns true if child is parent or under parent, `.` is the path of fixes that don't know what they would change, so it contains all paths.
+func pathContains(parent, child string) bool {
+	parent, child = filepath.Clean(parent), filepath.Clean(child)
+	if parent == "." || parent == child {
+		return true
+	}
+	if absParent, err := filepath.Abs(parent); err == nil {
+		parent = absParent
+	}
+	if absChild, err := filepath.Abs(child); err == nil {
+		child = absChild
+	}
+	if parent == child {
+		return true
+	}
+	return strings.HasPrefix(child, strings.TrimSuffix(parent, string(filepath.Separator))+string(filepath.Separator))
+}
@@ -0,0 +1,216 @@
+package pkg
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *greptConfigSuite) TestPlan_ParallelApply() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "local_file" license {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/LICENSE"]
+		content  = "MIT"
+	}
+
+	fix "copy_file" license {
+		rule_ids   = [rule.must_be_true.sample.id]
+		src        = "/LICENSE"
+		dest       = "/docs/LICENSE"
+		depends_on = [fix.local_file.license]
+	}
+
+	fix "local_file" readme {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/README.md"]
+		content  = "readme"
+	}
+
+	fix "rename_file" readme {
+		rule_ids   = [rule.must_be_true.sample.id]
+		old_name   = "/README.md"
+		new_name   = "/README"
+		depends_on = [fix.local_file.readme]
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	plan.Parallelism = 4
+
+	require.NoError(t, plan.Apply())
+	for path, want := range map[string]string{
+		"/LICENSE":      "MIT",
+		"/docs/LICENSE": "MIT",
+		"/README":       "readme",
+	} {
+		got, err := afero.ReadFile(s.fs, path)
+		require.NoError(t, err, path)
+		s.Equal(want, string(got), path)
+	}
+}
+
+func (s *greptConfigSuite) TestPlan_ParallelApplyShouldRunIndependentFixesConcurrently() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "local_file" a {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/a"]
+		content  = "a"
+	}
+
+	fix "local_file" b {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/b"]
+		content  = "b"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	plan.Parallelism = 2
+
+	// each fix waits for the other one to start, they would time out if they were applied one by one
+	started := make(map[string]chan struct{})
+	for _, name := range []string{"fix.local_file.a", "fix.local_file.b"} {
+		started[name] = make(chan struct{})
+	}
+	err = plan.applyFixes(func(fix Fix) error {
+		close(started[fix.Address()])
+		for _, c := range started {
+			select {
+			case <-c:
+			case <-time.After(5 * time.Second):
+				s.Fail("independent fixes are not applied concurrently")
+			}
+		}
+		return nil
+	})
+	s.NoError(err)
+}
+
+func TestFixesConflict(t *testing.T) {
+	cases := []struct {
+		desc string
+		a    Fix
+		b    Fix
+		want bool
+	}{
+		{
+			desc: "different files",
+			a:    &LocalFileFix{Paths: []string{"/a"}},
+			b:    &RmLocalFileFix{Paths: []string{"/b"}},
+			want: false,
+		},
+		{
+			desc: "same file",
+			a:    &LocalFileFix{Paths: []string{"/a"}},
+			b:    &RmLocalFileFix{Paths: []string{"/a"}},
+			want: true,
+		},
+		{
+			desc: "file under folder",
+			a:    &RmLocalFileFix{Paths: []string{"/docs"}},
+			b:    &LocalFileFix{Paths: []string{"/docs/readme.md"}},
+			want: true,
+		},
+		{
+			desc: "read touched file",
+			a:    &LocalFileFix{Paths: []string{"/LICENSE"}},
+			b:    &CopyFileFix{Src: "/LICENSE", Dest: "/docs/LICENSE"},
+			want: true,
+		},
+		{
+			desc: "read the same file",
+			a:    &CopyFileFix{Src: "/LICENSE", Dest: "/a/LICENSE"},
+			b:    &CopyFileFix{Src: "/LICENSE", Dest: "/b/LICENSE"},
+			want: false,
+		},
+		{
+			desc: "undeclared local_shell",
+			a:    &LocalShellFix{},
+			b:    &LocalFileFix{Paths: []string{"/a"}},
+			want: true,
+		},
+		{
+			desc: "declared local_shell",
+			a:    &LocalShellFix{TouchedPaths: []string{"/go.sum"}},
+			b:    &LocalFileFix{Paths: []string{"/a"}},
+			want: false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert.Equal(t, c.want, fixesConflict(c.a, c.b))
+			assert.Equal(t, c.want, fixesConflict(c.b, c.a))
+		})
+	}
+}
+
+func (s *greptConfigSuite) TestPlan_ParallelApplyShouldKeepDependencyOrderOfSavedPlan() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "local_file" a {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/a"]
+		content  = "a"
+	}
+
+	fix "local_file" b {
+		rule_ids   = [rule.must_be_true.sample.id]
+		paths      = ["/b"]
+		content    = "b"
+		depends_on = [fix.local_file.a]
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.NoError(t, plan.Save("/plan.json"))
+	loaded, err := LoadPlan("/plan.json")
+	require.NoError(t, err)
+	loaded.Parallelism = 2
+
+	graph, err := loaded.Graph()
+	require.NoError(t, err)
+	s.Contains(graph, `"fix.local_file.a" -> "fix.local_file.b"`)
+
+	// fixes don't conflict, only the saved dependency keeps b waiting for a
+	var applied []string
+	mu := sync.Mutex{}
+	err = loaded.applyFixes(func(fix Fix) error {
+		if fix.Address() == "fix.local_file.a" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		applied = append(applied, fix.Address())
+		return nil
+	})
+	s.NoError(err)
+	s.Equal([]string{"fix.local_file.a", "fix.local_file.b"}, applied)
+}
@@ -5,6 +5,9 @@ import (
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/Azure/golden"
@@ -96,3 +99,499 @@ func (s *greptConfigSuite) TestUnTriggeredFixShouldNotBeApplied() {
 	s.NoError(err)
 	s.False(exists)
 }
+
+func (s *greptConfigSuite) TestPlan_BlockingRules() {
+	t := s.T()
+	content := `
+	locals {
+		items = toset(["a", "b"])
+	}
+
+	rule "must_be_true" license {
+		condition = false
+	}
+
+	rule "must_be_true" readme {
+		for_each = local.items
+		condition = false
+	}
+
+	rule "must_be_true" pass {
+		condition = true
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 3)
+
+	cases := []struct {
+		desc      string
+		selectors []string
+		want      int
+	}{
+		{
+			desc: "no selector",
+			want: 3,
+		},
+		{
+			desc:      "exact address",
+			selectors: []string{"rule.must_be_true.license"},
+			want:      1,
+		},
+		{
+			desc:      "address without for_each key",
+			selectors: []string{"rule.must_be_true.readme"},
+			want:      2,
+		},
+		{
+			desc:      "address with for_each key",
+			selectors: []string{"rule.must_be_true.readme[a]"},
+			want:      1,
+		},
+		{
+			desc:      "address with quoted for_each key",
+			selectors: []string{`rule.must_be_true.readme["a"]`},
+			want:      1,
+		},
+		{
+			desc:      "wildcard",
+			selectors: []string{"rule.must_be_true.*"},
+			want:      3,
+		},
+		{
+			desc:      "passed rule",
+			selectors: []string{"rule.must_be_true.pass"},
+			want:      0,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			blocking, err := plan.BlockingRules(c.selectors)
+			s.NoError(err)
+			s.Len(blocking, c.want)
+		})
+	}
+}
+
+func (s *greptConfigSuite) TestPlan_TransactionalApplyShouldRollbackOnFailure() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "local_file" readme {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/README.md", "/docs/new.md"]
+		content  = "changed"
+	}
+
+	fix "rename_file" readme {
+		rule_ids   = [rule.must_be_true.sample.id]
+		old_name   = "/README.md"
+		new_name   = "/README"
+		depends_on = [fix.local_file.readme]
+	}
+
+	fix "copy_file" missing {
+		rule_ids   = [rule.must_be_true.sample.id]
+		src        = "/missing"
+		dest       = "/dest"
+		depends_on = [fix.rename_file.readme]
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl", "/README.md"}, []string{content, "readme"})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	plan.Transactional = true
+
+	err = plan.Apply()
+	require.Error(t, err)
+	s.Contains(err.Error(), "fix.copy_file.missing")
+	s.Contains(err.Error(), "rolled back")
+	readme, err := afero.ReadFile(s.fs, "/README.md")
+	require.NoError(t, err)
+	s.Equal("readme", string(readme))
+	for _, p := range []string{"/README", "/docs", "/dest"} {
+		exists, err := afero.Exists(s.fs, p)
+		s.NoError(err)
+		s.False(exists, p)
+	}
+}
+
+func (s *greptConfigSuite) TestPlan_VerifyAfterApply() {
+	t := s.T()
+	content := `
+	rule "file_exist" a {
+		glob = "/a"
+	}
+
+	rule "file_exist" c {
+		glob = "/c"
+	}
+
+	rule "must_be_true" ineffective {
+		condition = false
+	}
+
+	rule "must_be_true" no_fix {
+		condition = false
+	}
+
+	fix "local_file" a {
+		rule_ids = [rule.file_exist.a.id]
+		paths    = ["/a"]
+		content  = "a"
+	}
+
+	fix "rm_local_file" c {
+		rule_ids = [rule.must_be_true.ineffective.id]
+		paths    = ["/c"]
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl", "/c"}, []string{content, "c"})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.NoError(t, plan.Apply())
+
+	v, err := plan.Verify()
+	require.NoError(t, err)
+
+	addresses := func(rules []*FailedRule) []string {
+		var r []string
+		for _, fr := range rules {
+			r = append(r, fr.Address())
+		}
+		return r
+	}
+	s.Equal([]string{"rule.file_exist.a"}, addresses(v.Resolved))
+	s.Equal([]string{"rule.must_be_true.ineffective", "rule.must_be_true.no_fix"}, addresses(v.StillFailing))
+	s.Equal([]string{"rule.file_exist.c"}, addresses(v.NewlyFailing))
+	s.Equal(map[string][]string{
+		"rule.must_be_true.ineffective": {"fix.rm_local_file.c"},
+	}, v.IneffectiveFixes)
+	s.True(v.Failed())
+	output := v.String()
+	s.Contains(output, "rule.file_exist.a resolved")
+	s.Contains(output, "rule.must_be_true.ineffective still failing, applied fix.rm_local_file.c did not fix it")
+	s.Contains(output, "rule.must_be_true.no_fix still failing, no fix for it")
+	s.Contains(output, "rule.file_exist.c newly failing")
+}
+
+func (s *greptConfigSuite) TestPlan_VerifyShouldReevaluateLocalsAndRulesThatRulesDependOn() {
+	t := s.T()
+	content := `
+	locals {
+		a_exists = length(rule.file_exist.a.match_files) > 0
+	}
+
+	rule "file_exist" a {
+		glob = "/a"
+	}
+
+	rule "must_be_true" a_exists {
+		condition = local.a_exists
+	}
+
+	fix "local_file" a {
+		rule_ids = [rule.file_exist.a.id]
+		paths    = ["/a"]
+		content  = "a"
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 2)
+	require.NoError(t, plan.Apply())
+
+	v, err := plan.Verify()
+	require.NoError(t, err)
+	s.False(v.Failed())
+	s.Len(v.Resolved, 2)
+	s.Empty(v.StillFailing)
+	s.Empty(v.NewlyFailing)
+}
+
+func (s *greptConfigSuite) TestPlan_VerifyShouldNotReadDataSourcesAgain() {
+	t := s.T()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("MIT"))
+	}))
+	defer server.Close()
+	content := fmt.Sprintf(`
+	data "http" license {
+		url = "%s"
+	}
+
+	rule "file_hash" license {
+		glob      = "/LICENSE"
+		hash      = sha1(data.http.license.response_body)
+		algorithm = "sha1"
+	}
+
+	fix "local_file" license {
+		rule_ids = [rule.file_hash.license.id]
+		paths    = ["/LICENSE"]
+		content  = data.http.license.response_body
+	}
+	`, server.URL)
+	s.dummyFsWithFiles([]string{"test.grept.hcl", "/LICENSE"}, []string{content, "Apache"})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	require.NoError(t, plan.Apply())
+
+	v, err := plan.Verify()
+	require.NoError(t, err)
+	s.False(v.Failed())
+	s.Len(v.Resolved, 1)
+	s.Equal(1, requests)
+}
+
+func (s *greptConfigSuite) TestPlan_Diff() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "local_file" readme {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/README.md", "/docs/new.md"]
+		content  = "# Title\nnew line\n"
+	}
+
+	fix "copy_file" license {
+		rule_ids = [rule.must_be_true.sample.id]
+		src      = "/templates/LICENSE"
+		dest     = "/LICENSE"
+	}
+
+	fix "rm_local_file" obsolete {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/obsolete.txt"]
+	}
+
+	fix "local_shell" script {
+		rule_ids = [rule.must_be_true.sample.id]
+		inlines  = ["echo hello"]
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl", "/README.md", "/templates/LICENSE", "/obsolete.txt"}, []string{content, "# Title\nold line\n", "MIT\n", "obsolete\n"})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+
+	diff, err := plan.Diff()
+	require.NoError(t, err)
+	s.Contains(diff, "fix.local_shell.script runs external commands, its changes can't be previewed")
+	s.Contains(diff, "--- a/README.md\n+++ b/README.md\n@@ -1,2 +1,2 @@\n # Title\n-old line\n+new line\n")
+	s.Contains(diff, "--- /dev/null\n+++ b/docs/new.md\n")
+	s.Contains(diff, "--- /dev/null\n+++ b/LICENSE\n@@ -0,0 +1 @@\n+MIT\n")
+	s.Contains(diff, "--- a/obsolete.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-obsolete\n")
+	s.NotContains(diff, "templates")
+
+	readme, err := afero.ReadFile(s.fs, "/README.md")
+	require.NoError(t, err)
+	s.Equal("# Title\nold line\n", string(readme))
+	for _, p := range []string{"/docs", "/LICENSE"} {
+		exists, err := afero.Exists(s.fs, p)
+		s.NoError(err)
+		s.False(exists, p)
+	}
+	exists, err := afero.Exists(s.fs, "/obsolete.txt")
+	s.NoError(err)
+	s.True(exists)
+}
+
+func (s *greptConfigSuite) TestPlan_DiffShouldNotChangeFsFactory() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "local_file" readme {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/README.md"]
+		content  = "new"
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl", "/README.md"}, []string{content, "old"})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+
+	// Diff might run while fixes are applied in other goroutines, they must keep using the real file system
+	var changed atomic.Bool
+	done := make(chan struct{})
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				if FsFactory() != s.fs {
+					changed.Store(true)
+				}
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		_, err = plan.Diff()
+		require.NoError(t, err)
+	}
+	close(done)
+	<-watched
+	s.False(changed.Load())
+
+	require.NoError(t, plan.Apply())
+	readme, err := afero.ReadFile(s.fs, "/README.md")
+	require.NoError(t, err)
+	s.Equal("new", string(readme))
+}
+
+func (s *greptConfigSuite) TestPlan_SaveAndLoad() {
+	t := s.T()
+	content := `
+	locals {
+		items = toset(["a", "b"])
+	}
+
+	rule "must_be_true" sample {
+		condition     = false
+		severity      = "warning"
+		tags          = ["docs"]
+	}
+
+	fix "local_file" readme {
+		for_each = local.items
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/${each.value}.md"]
+		content  = "content ${each.value}"
+	}
+
+	fix "rm_local_file" obsolete {
+		rule_ids   = [rule.must_be_true.sample.id]
+		paths      = ["/obsolete.txt"]
+		depends_on = [fix.local_file.readme]
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl", "/obsolete.txt"}, []string{content, "obsolete"})
+	config, err := BuildGreptConfig("", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.NoError(t, plan.Save("/plan.json"))
+
+	// changes to config after plan should not affect the saved plan, but would be treated as drift
+	loaded, err := LoadPlan("/plan.json")
+	require.NoError(t, err)
+	require.Len(t, loaded.FailedRules, 1)
+	s.Equal("rule.must_be_true.sample", loaded.FailedRules[0].Address())
+	s.Equal(SeverityWarning, loaded.FailedRules[0].GetSeverity())
+	s.Equal([]string{"docs"}, loaded.FailedRules[0].GetTags())
+	s.Len(loaded.Fixes, 3)
+	s.Contains(loaded.String(), "fix.local_file.readme[a] would be apply")
+
+	require.NoError(t, loaded.Apply())
+	for _, name := range []string{"a", "b"} {
+		c, err := afero.ReadFile(s.fs, fmt.Sprintf("/%s.md", name))
+		require.NoError(t, err)
+		s.Equal("content "+name, string(c))
+	}
+	exists, err := afero.Exists(s.fs, "/obsolete.txt")
+	s.NoError(err)
+	s.False(exists)
+}
+
+func (s *greptConfigSuite) TestPlan_LoadShouldFailOnDrift() {
+	t := s.T()
+	content := `
+	rule "must_be_true" sample {
+		condition = false
+	}
+
+	fix "local_file" readme {
+		rule_ids = [rule.must_be_true.sample.id]
+		paths    = ["/README.md"]
+		content  = "readme"
+	}
+
+	rule "file_content" no_todo {
+		glob    = "/src/*.go"
+		literal = ["TODO"]
+		mode    = "must_not_match"
+	}
+	`
+	cases := []struct {
+		desc  string
+		drift func()
+		want  string
+	}{
+		{
+			desc: "file_read_by_rule_changed",
+			drift: func() {
+				s.dummyFsWithFiles([]string{"/src/a.go"}, []string{"// TODO: changed"})
+			},
+			want: "/src/*.go read by rule.file_content.no_todo",
+		},
+		{
+			desc: "file_matched_by_rule_added",
+			drift: func() {
+				s.dummyFsWithFiles([]string{"/src/b.go"}, []string{"package src"})
+			},
+			want: "/src/*.go read by rule.file_content.no_todo",
+		},
+		{
+			desc: "touched_file_changed",
+			drift: func() {
+				s.dummyFsWithFiles([]string{"/README.md"}, []string{"changed"})
+			},
+			want: "/README.md",
+		},
+		{
+			desc: "config_changed",
+			drift: func() {
+				s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{content + "\n"})
+			},
+			want: "config /cfg",
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			s.fs = afero.NewMemMapFs()
+			s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl", "/src/a.go"}, []string{content, "// TODO"})
+			config, err := BuildGreptConfig("", "/cfg", nil, nil)
+			require.NoError(t, err)
+			plan, err := RunGreptPlan(config)
+			require.NoError(t, err)
+			require.NoError(t, plan.Save("/plan.json"))
+			_, err = LoadPlan("/plan.json")
+			require.NoError(t, err)
+			c.drift()
+			_, err = LoadPlan("/plan.json")
+			require.Error(t, err)
+			s.Contains(err.Error(), "drifted")
+			s.Contains(err.Error(), c.want)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/ahmetb/go-linq/v3"
+)
+
+// ApplyVerification compares failed rules of the plan before apply with rules re-checked after apply.
+type ApplyVerification struct {
+	// Resolved are rules that failed before apply and pass now.
+	Resolved []*FailedRule
+	// StillFailing are rules that failed both before and after apply.
+	StillFailing []*FailedRule
+	// NewlyFailing are rules that passed before apply and fail now.
+	NewlyFailing []*FailedRule
+	// IneffectiveFixes maps addresses of still failing rules to addresses of fixes that were applied for them but didn't fix anything.
+	IneffectiveFixes map[string][]string
+}
+
+// Verify re-checks all selected rules after apply. Rules and locals are decoded and executed again in dependency order, so they see the changes made by fixes,
+// while `data` blocks keep the values read during plan, so `data` blocks like `http` are not read again.
+func (p *GreptPlan) Verify() (*ApplyVerification, error) {
+	if p.c == nil {
+		return nil, fmt.Errorf("a plan loaded from a saved plan file has no config to verify rules with")
+	}
+	if err := p.reevaluate(); err != nil {
+		return nil, err
+	}
+	before := make(map[string]*FailedRule)
+	for _, fr := range p.FailedRules {
+		before[fr.Address()] = fr
+	}
+	known := make(map[string]bool)
+	for _, fr := range p.BaselinedRules {
+		known[fr.Address()] = true
+	}
+	for _, sr := range p.SuppressedRules {
+		known[sr.Address()] = true
+	}
+	v := &ApplyVerification{
+		IneffectiveFixes: make(map[string][]string),
+	}
+	failing := make(map[string]bool)
+	for _, r := range golden.Blocks[Rule](p.c) {
+		after, _, err := p.c.failedRule(r)
+		if err != nil {
+			return nil, err
+		}
+		if after == nil {
+			continue
+		}
+		failing[after.Address()] = true
+		old, ok := before[after.Address()]
+		if !ok {
+			if !known[after.Address()] {
+				v.NewlyFailing = append(v.NewlyFailing, after)
+			}
+			continue
+		}
+		v.StillFailing = append(v.StillFailing, after)
+		if fixes := p.fixAddresses(old); len(fixes) > 0 {
+			v.IneffectiveFixes[after.Address()] = fixes
+		}
+	}
+	for _, fr := range p.FailedRules {
+		if !failing[fr.Address()] {
+			v.Resolved = append(v.Resolved, fr)
+		}
+	}
+	for _, rules := range [][]*FailedRule{v.Resolved, v.StillFailing, v.NewlyFailing} {
+		sort.Slice(rules, func(i, j int) bool {
+			return rules[i].Address() < rules[j].Address()
+		})
+	}
+	return v, nil
+}
+
+// reevaluate decodes and executes blocks again in dependency order, `data` blocks are reused as they are, fixes and variables don't read files so they're skipped.
+func (p *GreptPlan) reevaluate() error {
+	return golden.Traverse[golden.Block](p.c.BaseConfig, func(b golden.Block) error {
+		switch b.(type) {
+		case Data, Fix, golden.Variable:
+			return nil
+		}
+		if err := golden.Decode(b); err != nil {
+			return fmt.Errorf("%s(%s) decode error: %+v", b.Address(), b.HclBlock().Range().String(), err)
+		}
+		pb, ok := b.(golden.PlanBlock)
+		if !ok {
+			return nil
+		}
+		if err := pb.ExecuteDuringPlan(); err != nil {
+			return fmt.Errorf("%s(%s) exec error: %+v", b.Address(), b.HclBlock().Range().String(), err)
+		}
+		return nil
+	})
+}
+
+// Failed returns true when there's any newly failing rule, or any still failing rule that has fixes applied.
+// Still failing rules without any fix are not treated as failures since apply has nothing to do with them.
+func (v *ApplyVerification) Failed() bool {
+	return len(v.NewlyFailing) > 0 || len(v.IneffectiveFixes) > 0
+}
+
+func (v *ApplyVerification) String() string {
+	sb := strings.Builder{}
+	for _, fr := range v.Resolved {
+		fmt.Fprintf(&sb, "%s resolved\n", fr.Address())
+	}
+	for _, fr := range v.StillFailing {
+		if fixes, ok := v.IneffectiveFixes[fr.Address()]; ok {
+			fmt.Fprintf(&sb, "%s still failing, applied %s did not fix it: %s\n", fr.Address(), strings.Join(fixes, ", "), fr.CheckError.Error())
+			continue
+		}
+		fmt.Fprintf(&sb, "%s still failing, no fix for it: %s\n", fr.Address(), fr.CheckError.Error())
+	}
+	for _, fr := range v.NewlyFailing {
+		fmt.Fprintf(&sb, "%s newly failing: %s\n", fr.Address(), fr.CheckError.Error())
+	}
+	return sb.String()
+}
+
+func (p *GreptPlan) fixAddresses(fr *FailedRule) []string {
+	var addresses []string
+	for _, f := range p.sortedFixes() {
+		if linq.From(f.GetRuleIds()).Contains(fr.Id()) {
+			addresses = append(addresses, f.Address())
+		}
+	}
+	return addresses
+}
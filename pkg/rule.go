@@ -1,27 +1,108 @@
 package pkg
 
-import "github.com/Azure/golden"
+import (
+	"fmt"
+
+	"github.com/Azure/golden"
+	"github.com/ahmetb/go-linq/v3"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/spf13/afero"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+var Severities = []string{SeverityError, SeverityWarning, SeverityInfo}
 
 type Rule interface {
 	golden.PlanBlock
 	CheckError() error
+	GetSeverity() string
+	GetDescription() string
+	GetRemediationUrl() string
+	GetTags() []string
 	// discriminator func
 	Rule()
 	setCheckError(error)
+	inputWalkers() []*fileWalker
 }
 
+var _ golden.BaseDecode = &BaseRule{}
+
 type BaseRule struct {
-	checkErr error
+	checkErr       error
+	inputs         []*fileWalker
+	Severity       string   `json:"severity" hcl:"severity,optional"`
+	Description    string   `json:"description" hcl:"description,optional"`
+	RemediationUrl string   `json:"remediation_url" hcl:"remediation_url,optional"`
+	Tags           []string `json:"tags" hcl:"tags,optional"`
 }
 
 func (br *BaseRule) BlockType() string {
 	return "rule"
 }
 
+func (br *BaseRule) BaseDecode(hb *golden.HclBlock, evalContext *hcl.EvalContext) error {
+	*br = BaseRule{
+		Severity: SeverityError,
+	}
+	for name, target := range map[string]any{
+		"severity":        &br.Severity,
+		"description":     &br.Description,
+		"remediation_url": &br.RemediationUrl,
+		"tags":            &br.Tags,
+	} {
+		attr, ok := hb.Body.Attributes[name]
+		if !ok {
+			continue
+		}
+		if diag := gohcl.DecodeExpression(attr.Expr, evalContext, target); diag.HasErrors() {
+			return diag
+		}
+	}
+	if !linq.From(Severities).Contains(br.Severity) {
+		return fmt.Errorf("invalid severity %s, valid values are: error, warning, info", br.Severity)
+	}
+	return nil
+}
+
 func (br *BaseRule) CheckError() error {
 	return br.checkErr
 }
 
+// GetSeverity returns `error` when severity is not set.
+func (br *BaseRule) GetSeverity() string {
+	if br == nil || br.Severity == "" {
+		return SeverityError
+	}
+	return br.Severity
+}
+
+func (br *BaseRule) GetDescription() string {
+	if br == nil {
+		return ""
+	}
+	return br.Description
+}
+
+func (br *BaseRule) GetRemediationUrl() string {
+	if br == nil {
+		return ""
+	}
+	return br.RemediationUrl
+}
+
+func (br *BaseRule) GetTags() []string {
+	if br == nil {
+		return nil
+	}
+	return br.Tags
+}
+
 func (br *BaseRule) Rule() {}
 
 func (br *BaseRule) AddressLength() int { return 3 }
@@ -33,3 +114,34 @@ func (br *BaseRule) CanExecutePrePlan() bool {
 func (br *BaseRule) setCheckError(err error) {
 	br.checkErr = err
 }
+
+// glob returns paths matched by the walker, the walker is recorded as an input of the rule, so a saved plan could tell whether files that the rule read have changed.
+func (br *BaseRule) glob(fs afero.Fs, w *fileWalker) ([]string, error) {
+	br.recordInput(w)
+	return w.Glob(fs)
+}
+
+// recordInput records a walker that finds files the rule reads, a file that the rule reads directly could be recorded by a walker with the file's path as glob.
+func (br *BaseRule) recordInput(w *fileWalker) {
+	if br != nil {
+		br.inputs = append(br.inputs, w)
+	}
+}
+
+func (br *BaseRule) inputWalkers() []*fileWalker {
+	if br == nil {
+		return nil
+	}
+	return br.inputs
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
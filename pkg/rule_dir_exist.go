@@ -2,8 +2,12 @@ package pkg
 
 import (
 	"fmt"
-	"github.com/Azure/golden"
+	"path"
+	"path/filepath"
+	"strings"
 
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
 	"github.com/spf13/afero"
 )
 
@@ -12,8 +16,21 @@ var _ Rule = &DirExistRule{}
 type DirExistRule struct {
 	*golden.BaseBlock
 	*BaseRule
-	Dir         string `hcl:"dir"`
-	FailOnExist bool   `hcl:"fail_on_exist,optional"`
+	Dir               string       `hcl:"dir"`
+	Exclude           []string     `hcl:"exclude,optional"`
+	RespectGitIgnore  bool         `hcl:"respect_gitignore,optional"`
+	FailOnExist       bool         `hcl:"fail_on_exist,optional" validate:"conflict_with=Empty RequiredChildren ForbiddenChildren"`
+	Empty             *bool        `hcl:"empty,optional"`
+	RequiredChildren  []string     `hcl:"required_children,optional"`
+	ForbiddenChildren []string     `hcl:"forbidden_children,optional"`
+	MatchDirs         []string     `attribute:"match_dirs"`
+	MismatchedDirs    []string     `attribute:"mismatched_dirs"`
+	Dirs              []DirListing `attribute:"dirs"`
+}
+
+type DirListing struct {
+	Dir     string   `attribute:"dir"`
+	Entries []string `attribute:"entries"`
 }
 
 func (d *DirExistRule) Type() string {
@@ -21,18 +38,128 @@ func (d *DirExistRule) Type() string {
 }
 
 func (d *DirExistRule) ExecuteDuringPlan() error {
+	d.MatchDirs, d.MismatchedDirs, d.Dirs = nil, nil, nil
 	fs := FsFactory()
-	exists, err := afero.Exists(fs, d.Dir)
+	finds, err := d.glob(fs, newFileWalker(d.Dir, d.Exclude, d.RespectGitIgnore))
 	if err != nil {
-		return err
+		return fmt.Errorf("error on glob directories %s, %s", d.Dir, d.Address())
 	}
+	for _, find := range finds {
+		isDir, err := afero.IsDir(fs, find)
+		if err != nil {
+			return err
+		}
+		if !isDir {
+			continue
+		}
+		d.MatchDirs = append(d.MatchDirs, find)
+		d.recordInput(newFileWalker(path.Join(find, "*"), nil, false))
+		entries, err := listDir(fs, find)
+		if err != nil {
+			return err
+		}
+		d.Dirs = append(d.Dirs, DirListing{
+			Dir:     find,
+			Entries: entries,
+		})
+	}
+	exists := len(d.MatchDirs) > 0
 	if d.FailOnExist && exists {
-		d.setCheckError(fmt.Errorf("directory exists: %s", d.Dir))
+		d.setCheckError(fmt.Errorf("directory exists: %s", strings.Join(d.MatchDirs, ", ")))
 		return nil
 	}
 	if !d.FailOnExist && !exists {
 		d.setCheckError(fmt.Errorf("directory does not exist: %s", d.Dir))
 		return nil
 	}
+	var checkErr error
+	for _, listing := range d.Dirs {
+		errs, err := d.checkDir(fs, listing)
+		if err != nil {
+			return err
+		}
+		if len(errs) == 0 {
+			continue
+		}
+		d.MismatchedDirs = append(d.MismatchedDirs, listing.Dir)
+		checkErr = multierror.Append(checkErr, errs...)
+	}
+	if checkErr != nil {
+		d.setCheckError(checkErr)
+	}
 	return nil
 }
+
+func (d *DirExistRule) violatingFiles() []string {
+	if d.FailOnExist {
+		return d.MatchDirs
+	}
+	return d.MismatchedDirs
+}
+
+// checkDir returns the violations of a matched directory, children patterns are globs relative to the directory,
+// a pattern that ends with `/` only matches directories.
+func (d *DirExistRule) checkDir(fs afero.Fs, listing DirListing) ([]error, error) {
+	var errs []error
+	if d.Empty != nil && *d.Empty && len(listing.Entries) > 0 {
+		errs = append(errs, fmt.Errorf("directory is not empty: %s", listing.Dir))
+	}
+	if d.Empty != nil && !*d.Empty && len(listing.Entries) == 0 {
+		errs = append(errs, fmt.Errorf("directory is empty: %s", listing.Dir))
+	}
+	for _, child := range d.RequiredChildren {
+		children, err := d.globChildren(fs, listing.Dir, child)
+		if err != nil {
+			return nil, fmt.Errorf("error on glob children %s, %s: %+v", child, d.Address(), err)
+		}
+		if len(children) == 0 {
+			errs = append(errs, fmt.Errorf("required %s not found in directory %s", child, listing.Dir))
+		}
+	}
+	for _, child := range d.ForbiddenChildren {
+		children, err := d.globChildren(fs, listing.Dir, child)
+		if err != nil {
+			return nil, fmt.Errorf("error on glob children %s, %s: %+v", child, d.Address(), err)
+		}
+		if len(children) > 0 {
+			errs = append(errs, fmt.Errorf("forbidden %s found in directory %s: %s", child, listing.Dir, strings.Join(children, ", ")))
+		}
+	}
+	return errs, nil
+}
+
+// listDir returns names of the direct children of the directory in lexical order, names of directories end with `/`.
+func listDir(fs afero.Fs, dir string) ([]string, error) {
+	infos, err := afero.ReadDir(fs, filepath.FromSlash(dir))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]string, 0, len(infos))
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() {
+			name += "/"
+		}
+		entries = append(entries, name)
+	}
+	return entries, nil
+}
+
+func (d *DirExistRule) globChildren(fs afero.Fs, dir, pattern string) ([]string, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	matches, err := d.glob(fs, newFileWalker(path.Join(dir, pattern), nil, false))
+	if err != nil || !dirOnly {
+		return matches, err
+	}
+	var dirs []string
+	for _, m := range matches {
+		isDir, err := afero.IsDir(fs, m)
+		if err != nil {
+			return nil, err
+		}
+		if isDir {
+			dirs = append(dirs, m)
+		}
+	}
+	return dirs, nil
+}
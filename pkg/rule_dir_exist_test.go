@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"github.com/Azure/golden"
 	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"github.com/zclconf/go-cty/cty"
 	"testing"
 )
 
@@ -87,3 +89,129 @@ rule dir_exist test {
 		})
 	}
 }
+
+func (s *dirExistRuleSuite) TestDirExistRule_Children() {
+	s.dummyFsWithFiles([]string{
+		"/modules/network/README.md",
+		"/modules/network/examples/basic/main.tf",
+		"/modules/storage/README.md",
+		"/modules/storage/examples",
+		"/modules/storage/.terraform/lock",
+	}, []string{"", "", "", "", ""})
+	_ = s.fs.Mkdir("/modules/compute", 0755)
+	empty := true
+	notEmpty := false
+	cases := []struct {
+		desc           string
+		rule           *DirExistRule
+		wantError      bool
+		wantMismatched []string
+	}{
+		{
+			desc: "glob",
+			rule: &DirExistRule{
+				Dir: "/modules/*",
+			},
+		},
+		{
+			desc: "files_do_not_count",
+			rule: &DirExistRule{
+				Dir: "/modules/*/README.md",
+			},
+			wantError: true,
+		},
+		{
+			desc: "required_children",
+			rule: &DirExistRule{
+				Dir:              "/modules/*",
+				RequiredChildren: []string{"README.md", "examples/"},
+			},
+			wantError:      true,
+			wantMismatched: []string{"/modules/compute", "/modules/storage"},
+		},
+		{
+			desc: "forbidden_children",
+			rule: &DirExistRule{
+				Dir:               "/modules/*",
+				ForbiddenChildren: []string{".terraform/"},
+			},
+			wantError:      true,
+			wantMismatched: []string{"/modules/storage"},
+		},
+		{
+			desc: "empty",
+			rule: &DirExistRule{
+				Dir:   "/modules/*",
+				Empty: &empty,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/modules/network", "/modules/storage"},
+		},
+		{
+			desc: "not_empty",
+			rule: &DirExistRule{
+				Dir:   "/modules/*",
+				Empty: &notEmpty,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/modules/compute"},
+		},
+		{
+			desc: "fail_on_exist_glob",
+			rule: &DirExistRule{
+				Dir:         "/modules/**/.terraform",
+				FailOnExist: true,
+			},
+			wantError: true,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			c.rule.BaseRule = new(BaseRule)
+			err := c.rule.ExecuteDuringPlan()
+			s.NoError(err)
+			if c.wantError {
+				s.Error(c.rule.CheckError())
+			} else {
+				s.NoError(c.rule.CheckError())
+			}
+			s.Equal(c.wantMismatched, c.rule.MismatchedDirs)
+		})
+	}
+}
+
+func (s *dirExistRuleSuite) TestDirExistRule_ExportDirs() {
+	s.dummyFsWithFiles([]string{"/modules/network/README.md", "/modules/network/examples/main.tf"}, []string{"", ""})
+	_ = s.fs.Mkdir("/modules/compute", 0755)
+	rule := &DirExistRule{
+		BaseRule: new(BaseRule),
+		Dir:      "/modules/*",
+	}
+	err := rule.ExecuteDuringPlan()
+	s.NoError(err)
+	s.Equal([]string{"/modules/compute", "/modules/network"}, rule.MatchDirs)
+	s.Equal([]DirListing{
+		{Dir: "/modules/compute", Entries: []string{}},
+		{Dir: "/modules/network", Entries: []string{"README.md", "examples/"}},
+	}, rule.Dirs)
+	value := golden.Value(rule)
+	dirs := value["dirs"]
+	s.Equal(2, dirs.LengthInt())
+	s.Equal(cty.StringVal("examples/"), dirs.Index(cty.NumberIntVal(1)).GetAttr("entries").Index(cty.NumberIntVal(1)))
+}
+
+func (s *dirExistRuleSuite) TestDirExistRule_FailOnExistConflictsWithChildren() {
+	t := s.T()
+	content := `
+	rule "dir_exist" modules {
+		dir               = "/modules/*"
+		fail_on_exist     = true
+		required_children = ["README.md"]
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
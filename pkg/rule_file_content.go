@@ -0,0 +1,144 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/spf13/afero"
+)
+
+var _ Rule = &FileContentRule{}
+
+type FileContentRule struct {
+	*golden.BaseBlock
+	*BaseRule
+	Glob             string             `hcl:"glob"`
+	Exclude          []string           `hcl:"exclude,optional"`
+	RespectGitIgnore bool               `hcl:"respect_gitignore,optional"`
+	Regexes          []string           `hcl:"regex,optional" validate:"at_least_one_of=Regexes Literals"`
+	Literals         []string           `hcl:"literal,optional" validate:"at_least_one_of=Regexes Literals"`
+	Mode             string             `hcl:"mode,optional" default:"must_match" validate:"oneof=must_match must_not_match"`
+	MatchedFiles     []string           `attribute:"matched_files"`
+	MismatchedFiles  []string           `attribute:"mismatched_files"`
+	Matches          []FileContentMatch `attribute:"matches"`
+}
+
+type FileContentMatch struct {
+	File    string   `attribute:"file"`
+	Line    int      `attribute:"line"`
+	Pattern string   `attribute:"pattern"`
+	Text    string   `attribute:"text"`
+	Groups  []string `attribute:"groups"`
+}
+
+func (f *FileContentRule) Type() string {
+	return "file_content"
+}
+
+func (f *FileContentRule) ExecuteDuringPlan() error {
+	patterns, err := f.patterns()
+	if err != nil {
+		return err
+	}
+	f.MatchedFiles, f.MismatchedFiles, f.Matches = nil, nil, nil
+	fs := FsFactory()
+	files, err := f.glob(fs, newFileWalker(f.Glob, f.Exclude, f.RespectGitIgnore))
+	if err != nil {
+		return fmt.Errorf("error on glob files %s, %s", f.Glob, f.Address())
+	}
+	if len(files) == 0 && f.Mode == "must_match" {
+		f.setCheckError(fmt.Errorf("no files match path pattern: %s", f.Glob))
+		return nil
+	}
+	for _, file := range files {
+		isDir, err := afero.IsDir(fs, file)
+		if err != nil {
+			return err
+		}
+		if isDir {
+			continue
+		}
+		content, err := afero.ReadFile(fs, file)
+		if err != nil {
+			return err
+		}
+		matchAll := true
+		matchAny := false
+		for i, p := range patterns {
+			matches := f.find(file, content, p, f.pattern(i))
+			if len(matches) == 0 {
+				matchAll = false
+				continue
+			}
+			matchAny = true
+			f.Matches = append(f.Matches, matches...)
+		}
+		if matchAny {
+			f.MatchedFiles = append(f.MatchedFiles, file)
+		}
+		if (f.Mode == "must_match" && !matchAll) || (f.Mode == "must_not_match" && matchAny) {
+			f.MismatchedFiles = append(f.MismatchedFiles, file)
+		}
+	}
+	if len(f.MismatchedFiles) == 0 {
+		return nil
+	}
+	if f.Mode == "must_not_match" {
+		f.setCheckError(fmt.Errorf("forbidden content found in files: %s", strings.Join(f.MismatchedFiles, ", ")))
+		return nil
+	}
+	f.setCheckError(fmt.Errorf("required content not found in files: %s", strings.Join(f.MismatchedFiles, ", ")))
+	return nil
+}
+
+func (f *FileContentRule) violatingFiles() []string {
+	return f.MismatchedFiles
+}
+
+// patterns compiles regexes and literals into one list, literals follow regexes.
+func (f *FileContentRule) patterns() ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	for _, r := range f.Regexes {
+		p, err := regexp.Compile(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %s, %s: %+v", r, f.Address(), err)
+		}
+		patterns = append(patterns, p)
+	}
+	for _, l := range f.Literals {
+		patterns = append(patterns, regexp.MustCompile(regexp.QuoteMeta(l)))
+	}
+	return patterns, nil
+}
+
+func (f *FileContentRule) pattern(i int) string {
+	if i < len(f.Regexes) {
+		return f.Regexes[i]
+	}
+	return f.Literals[i-len(f.Regexes)]
+}
+
+func (f *FileContentRule) find(file string, content []byte, p *regexp.Regexp, pattern string) []FileContentMatch {
+	var matches []FileContentMatch
+	for _, loc := range p.FindAllSubmatchIndex(content, -1) {
+		m := FileContentMatch{
+			File:    file,
+			Line:    bytes.Count(content[:loc[0]], []byte("\n")) + 1,
+			Pattern: pattern,
+			Text:    string(content[loc[0]:loc[1]]),
+			Groups:  []string{},
+		}
+		for g := 2; g+1 < len(loc); g += 2 {
+			group := ""
+			if loc[g] >= 0 {
+				group = string(content[loc[g]:loc[g+1]])
+			}
+			m.Groups = append(m.Groups, group)
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
@@ -0,0 +1,201 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/golden"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type fileContentRuleSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestFileContentRuleSuite(t *testing.T) {
+	suite.Run(t, new(fileContentRuleSuite))
+}
+
+func (s *fileContentRuleSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *fileContentRuleSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *fileContentRuleSuite) TestFileContentRule_Check() {
+	s.dummyFsWithFiles([]string{"/README.md", "/docs/README.md", "/main.go"}, []string{
+		"# Title\nSee our support policy.\n",
+		"# Docs\n",
+		"package main\n\n// TODO(security): fix this\nfunc main() {}\n",
+	})
+	cases := []struct {
+		desc           string
+		rule           *FileContentRule
+		wantError      bool
+		wantMismatched []string
+	}{
+		{
+			desc: "must_match_all_files_match",
+			rule: &FileContentRule{
+				Glob:    "/README.md",
+				Regexes: []string{`(?i)support\s+policy`},
+				Mode:    "must_match",
+			},
+			wantError: false,
+		},
+		{
+			desc: "must_match_one_file_mismatch",
+			rule: &FileContentRule{
+				Glob:     "/*/README.md",
+				Literals: []string{"support policy"},
+				Mode:     "must_match",
+			},
+			wantError:      true,
+			wantMismatched: []string{"/docs/README.md"},
+		},
+		{
+			desc: "must_match_every_pattern",
+			rule: &FileContentRule{
+				Glob:     "/README.md",
+				Regexes:  []string{"Title"},
+				Literals: []string{"license"},
+				Mode:     "must_match",
+			},
+			wantError:      true,
+			wantMismatched: []string{"/README.md"},
+		},
+		{
+			desc: "must_match_no_file",
+			rule: &FileContentRule{
+				Glob:     "/CONTRIBUTING.md",
+				Literals: []string{"support policy"},
+				Mode:     "must_match",
+			},
+			wantError: true,
+		},
+		{
+			desc: "must_not_match_found",
+			rule: &FileContentRule{
+				Glob:     "/*.go",
+				Literals: []string{"TODO(security)"},
+				Mode:     "must_not_match",
+			},
+			wantError:      true,
+			wantMismatched: []string{"/main.go"},
+		},
+		{
+			desc: "must_not_match_not_found",
+			rule: &FileContentRule{
+				Glob:     "/*.md",
+				Literals: []string{"TODO(security)"},
+				Mode:     "must_not_match",
+			},
+			wantError: false,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			c.rule.BaseRule = new(BaseRule)
+			err := c.rule.ExecuteDuringPlan()
+			s.NoError(err)
+			if c.wantError {
+				s.Error(c.rule.CheckError())
+			} else {
+				s.NoError(c.rule.CheckError())
+			}
+			s.Equal(c.wantMismatched, c.rule.MismatchedFiles)
+		})
+	}
+}
+
+func (s *fileContentRuleSuite) TestFileContentRule_ExportMatches() {
+	s.dummyFsWithFiles([]string{"/main.go"}, []string{"package main\n\n// TODO(security): fix this\n// TODO(perf): later\n"})
+	rule := &FileContentRule{
+		BaseRule: new(BaseRule),
+		Glob:     "/main.go",
+		Regexes:  []string{`TODO\((\w+)\)`},
+		Mode:     "must_not_match",
+	}
+	err := rule.ExecuteDuringPlan()
+	s.NoError(err)
+	s.Equal([]string{"/main.go"}, rule.MatchedFiles)
+	s.Equal([]FileContentMatch{
+		{
+			File:    "/main.go",
+			Line:    3,
+			Pattern: `TODO\((\w+)\)`,
+			Text:    "TODO(security)",
+			Groups:  []string{"security"},
+		},
+		{
+			File:    "/main.go",
+			Line:    4,
+			Pattern: `TODO\((\w+)\)`,
+			Text:    "TODO(perf)",
+			Groups:  []string{"perf"},
+		},
+	}, rule.Matches)
+	value := golden.Value(rule)
+	matches := value["matches"]
+	s.Equal(2, matches.LengthInt())
+	s.Equal(cty.NumberIntVal(3), matches.Index(cty.NumberIntVal(0)).GetAttr("line"))
+}
+
+func (s *fileContentRuleSuite) TestFileContentRule_InvalidRegex() {
+	rule := &FileContentRule{
+		BaseRule: new(BaseRule),
+		Glob:     "/main.go",
+		Regexes:  []string{`TODO(`},
+		Mode:     "must_match",
+	}
+	err := rule.ExecuteDuringPlan()
+	s.Error(err)
+}
+
+func (s *fileContentRuleSuite) TestFileContentRule_Config() {
+	t := s.T()
+	content := `
+	rule "file_content" todo {
+		glob    = "/*.go"
+		literal = ["TODO(security)"]
+		mode    = "must_not_match"
+	}
+
+	fix "local_file" todo {
+		rule_ids = [rule.file_content.todo.id]
+		paths    = [for m in rule.file_content.todo.matches : "${m.file}.line${m.line}"]
+		content  = ""
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/main.go"}, []string{content, "package main\n// TODO(security)\n"})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	require.Len(t, plan.Fixes, 1)
+	fixes := golden.Blocks[Fix](config)
+	require.Len(t, fixes, 1)
+	s.Equal([]string{"/main.go.line2"}, fixes[0].(*LocalFileFix).Paths)
+}
+
+func (s *fileContentRuleSuite) TestFileContentRule_InvalidMode() {
+	t := s.T()
+	content := `
+	rule "file_content" todo {
+		glob    = "/*.go"
+		literal = ["TODO(security)"]
+		mode    = "must_contain"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
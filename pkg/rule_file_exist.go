@@ -2,8 +2,9 @@ package pkg
 
 import (
 	"fmt"
+	"strings"
+
 	"github.com/Azure/golden"
-	"github.com/spf13/afero"
 )
 
 var _ Rule = &FileExistRule{}
@@ -11,8 +12,14 @@ var _ Rule = &FileExistRule{}
 type FileExistRule struct {
 	*golden.BaseBlock
 	*BaseRule
-	Glob       string `hcl:"glob"`
-	MatchFiles []string
+	Glob             string   `hcl:"glob"`
+	Exclude          []string `hcl:"exclude,optional"`
+	RespectGitIgnore bool     `hcl:"respect_gitignore,optional"`
+	CaseInsensitive  bool     `hcl:"case_insensitive,optional"`
+	FailOnExist      bool     `hcl:"fail_on_exist,optional" validate:"conflict_with=MinCount MaxCount"`
+	MinCount         *int     `hcl:"min_count,optional" validate:"omitempty,min=0"`
+	MaxCount         *int     `hcl:"max_count,optional" validate:"omitempty,min=0"`
+	MatchFiles       []string `attribute:"match_files"`
 }
 
 func (f *FileExistRule) Type() string {
@@ -21,14 +28,46 @@ func (f *FileExistRule) Type() string {
 
 func (f *FileExistRule) ExecuteDuringPlan() error {
 	fs := FsFactory()
-	finds, err := afero.Glob(fs, f.Glob)
+	w := newFileWalker(f.Glob, f.Exclude, f.RespectGitIgnore)
+	w.caseInsensitive = f.CaseInsensitive
+	finds, err := f.glob(fs, w)
 	if err != nil {
 		return fmt.Errorf("error on glob files %s, %s", f.Glob, f.Address())
 	}
-	if len(finds) == 0 {
+	f.MatchFiles = finds
+	if f.FailOnExist {
+		if len(finds) > 0 {
+			f.setCheckError(fmt.Errorf("files match glob %s: %s, %s", f.Glob, strings.Join(finds, ", "), f.Address()))
+		}
+		return nil
+	}
+	minCount := 1
+	if f.MinCount != nil {
+		minCount = *f.MinCount
+	} else if f.MaxCount != nil && *f.MaxCount == 0 {
+		minCount = 0
+	}
+	if f.MaxCount != nil && *f.MaxCount < minCount {
+		return fmt.Errorf("max_count %d is less than min_count %d, %s", *f.MaxCount, minCount, f.Address())
+	}
+	if len(finds) == 0 && minCount > 0 {
 		f.setCheckError(fmt.Errorf("no match on glob %s, %s", f.Glob, f.Address()))
 		return nil
 	}
-	f.MatchFiles = finds
+	if len(finds) < minCount {
+		f.setCheckError(fmt.Errorf("%d files match glob %s, expected at least %d: %s, %s", len(finds), f.Glob, minCount, strings.Join(finds, ", "), f.Address()))
+		return nil
+	}
+	if f.MaxCount != nil && len(finds) > *f.MaxCount {
+		f.setCheckError(fmt.Errorf("%d files match glob %s, expected at most %d: %s, %s", len(finds), f.Glob, *f.MaxCount, strings.Join(finds, ", "), f.Address()))
+	}
+	return nil
+}
+
+// violatingFiles returns matched files when there are files that should not exist, a rule that fails on too few files has no violating files.
+func (f *FileExistRule) violatingFiles() []string {
+	if f.FailOnExist || (f.MaxCount != nil && len(f.MatchFiles) > *f.MaxCount) {
+		return f.MatchFiles
+	}
 	return nil
 }
@@ -1,8 +1,12 @@
 package pkg
 
 import (
+	"context"
 	"testing"
 
+	"github.com/Azure/golden"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -24,7 +28,10 @@ func (s *fileExistRuleSuite) TearDownTest() {
 }
 
 func (s *fileExistRuleSuite) TestFileExistRule_Check() {
-	s.dummyFsWithFiles([]string{"./file1.txt", "./file2.txt", "./file3.txt", "./pkg/sub/subfile1.txt"}, []string{"content", "content", "content", "content"})
+	s.dummyFsWithFiles([]string{"./file1.txt", "./file2.txt", "./file3.txt", "./pkg/sub/subfile1.txt", "./license.md"}, []string{"content", "content", "content", "content", "content"})
+	two := 2
+	four := 4
+	zero := 0
 
 	tests := []struct {
 		name      string
@@ -47,6 +54,78 @@ func (s *fileExistRuleSuite) TestFileExistRule_Check() {
 			},
 			wantError: true,
 		},
+		{
+			name: "fail on exist",
+			rule: &FileExistRule{
+				BaseRule:    new(BaseRule),
+				Glob:        "./file*.txt",
+				FailOnExist: true,
+			},
+			wantError: true,
+		},
+		{
+			name: "fail on exist no file",
+			rule: &FileExistRule{
+				BaseRule:    new(BaseRule),
+				Glob:        "./nofile.txt",
+				FailOnExist: true,
+			},
+			wantError: false,
+		},
+		{
+			name: "min count",
+			rule: &FileExistRule{
+				BaseRule: new(BaseRule),
+				Glob:     "./file*.txt",
+				MinCount: &four,
+			},
+			wantError: true,
+		},
+		{
+			name: "max count",
+			rule: &FileExistRule{
+				BaseRule: new(BaseRule),
+				Glob:     "./file*.txt",
+				MaxCount: &two,
+			},
+			wantError: true,
+		},
+		{
+			name: "within min and max count",
+			rule: &FileExistRule{
+				BaseRule: new(BaseRule),
+				Glob:     "./**/*.txt",
+				MinCount: &two,
+				MaxCount: &four,
+			},
+			wantError: false,
+		},
+		{
+			name: "max count zero",
+			rule: &FileExistRule{
+				BaseRule: new(BaseRule),
+				Glob:     "./nofile.txt",
+				MaxCount: &zero,
+			},
+			wantError: false,
+		},
+		{
+			name: "case sensitive",
+			rule: &FileExistRule{
+				BaseRule: new(BaseRule),
+				Glob:     "LICENSE*",
+			},
+			wantError: true,
+		},
+		{
+			name: "case insensitive",
+			rule: &FileExistRule{
+				BaseRule:        new(BaseRule),
+				Glob:            "LICENSE*",
+				CaseInsensitive: true,
+			},
+			wantError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -62,3 +141,65 @@ func (s *fileExistRuleSuite) TestFileExistRule_Check() {
 		})
 	}
 }
+
+func (s *fileExistRuleSuite) TestFileExistRule_MaxCountLessThanMinCount() {
+	two := 2
+	one := 1
+	rule := &FileExistRule{
+		BaseRule: new(BaseRule),
+		Glob:     "./file*.txt",
+		MinCount: &two,
+		MaxCount: &one,
+	}
+	s.Error(rule.ExecuteDuringPlan())
+}
+
+func (s *fileExistRuleSuite) TestFileExistRule_ExportMatchFiles() {
+	t := s.T()
+	content := `
+	rule "file_exist" license {
+		glob             = "/LICENSE*"
+		case_insensitive = true
+		max_count        = 1
+	}
+
+	rule "file_exist" upper_case_license {
+		glob = "/LICENSE"
+	}
+
+	fix "rename_file" license {
+		rule_ids = [rule.file_exist.upper_case_license.id]
+		old_name = rule.file_exist.license.match_files[0]
+		new_name = "/LICENSE"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/license.md"}, []string{content, "MIT"})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	fixes := golden.Blocks[Fix](config)
+	require.Len(t, fixes, 1)
+	s.Equal("/license.md", fixes[0].(*RenameFileFix).OldName)
+	require.NoError(t, plan.Apply())
+	exists, err := afero.Exists(s.fs, "/LICENSE")
+	require.NoError(t, err)
+	s.True(exists)
+}
+
+func (s *fileExistRuleSuite) TestFileExistRule_FailOnExistConflictsWithCount() {
+	t := s.T()
+	content := `
+	rule "file_exist" license {
+		glob          = "/LICENSE"
+		fail_on_exist = true
+		min_count     = 1
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
@@ -7,6 +7,9 @@ import (
 	"crypto/sha512"
 	"fmt"
 	"hash"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/Azure/golden"
 	"github.com/spf13/afero"
@@ -17,11 +20,50 @@ var _ Rule = &FileHashRule{}
 type FileHashRule struct {
 	*golden.BaseBlock
 	*BaseRule
-	Glob               string   `hcl:"glob"`
-	Hash               string   `hcl:"hash"`
-	Algorithm          string   `hcl:"algorithm,optional" default:"sha1"`
-	FailOnHashMismatch bool     `hcl:"fail_on_hash_mismatch,optional"`
-	HashMismatchFiles  []string `attribute:"hash_mismatch_files"`
+	Glob               string             `hcl:"glob"`
+	Exclude            []string           `hcl:"exclude,optional"`
+	RespectGitIgnore   bool               `hcl:"respect_gitignore,optional"`
+	Hash               string             `hcl:"hash,optional" validate:"at_least_one_of=Hash Hashes ExpectedContent"`
+	Hashes             []string           `hcl:"hashes,optional" validate:"at_least_one_of=Hash Hashes ExpectedContent"`
+	ExpectedContent    string             `hcl:"expected_content,optional" validate:"at_least_one_of=Hash Hashes ExpectedContent"`
+	Algorithm          string             `hcl:"algorithm,optional" default:"sha1"`
+	FailOnHashMismatch bool               `hcl:"fail_on_hash_mismatch,optional"`
+	Normalize          *FileHashNormalize `hcl:"normalize,block"`
+	HashMismatchFiles  []string           `attribute:"hash_mismatch_files"`
+	FileHashes         map[string]string  `attribute:"file_hashes"`
+}
+
+// FileHashNormalize defines how file content is normalized before hashing, so files that only differ in insignificant ways have the same hash.
+type FileHashNormalize struct {
+	LineEndings        bool     `hcl:"line_endings,optional"`
+	TrailingWhitespace bool     `hcl:"trailing_whitespace,optional"`
+	Placeholders       []string `hcl:"placeholders,optional"`
+}
+
+var trailingWhitespace = regexp.MustCompile(`[ \t]+(\n|$)`)
+
+// apply normalizes line endings first, then removes placeholders, then trailing whitespace.
+func (n *FileHashNormalize) apply(content []byte) ([]byte, error) {
+	if n == nil {
+		return content, nil
+	}
+	s := string(content)
+	if n.LineEndings {
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+		s = strings.ReplaceAll(s, "\r", "\n")
+	}
+	for _, p := range n.Placeholders {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid placeholder regex %s: %+v", p, err)
+		}
+		s = r.ReplaceAllString(s, "")
+	}
+	if n.TrailingWhitespace {
+		s = trailingWhitespace.ReplaceAllString(s, "$1")
+		s = strings.TrimRight(s, " \t\r\n")
+	}
+	return []byte(s), nil
 }
 
 func (fhr *FileHashRule) Type() string {
@@ -29,9 +71,10 @@ func (fhr *FileHashRule) Type() string {
 }
 
 func (fhr *FileHashRule) ExecuteDuringPlan() error {
+	fhr.HashMismatchFiles, fhr.FileHashes = nil, make(map[string]string)
 	// Use Glob to find files matching the path pattern
 	fs := FsFactory()
-	files, err := afero.Glob(fs, fhr.Glob)
+	files, err := fhr.glob(fs, newFileWalker(fhr.Glob, fhr.Exclude, fhr.RespectGitIgnore))
 	if err != nil {
 		return err
 	}
@@ -40,32 +83,32 @@ func (fhr *FileHashRule) ExecuteDuringPlan() error {
 		fhr.setCheckError(fmt.Errorf("no files match path pattern: %s", fhr.Glob))
 		return nil
 	}
+	expectedHashes, err := fhr.expectedHashes()
+	if err != nil {
+		return fmt.Errorf("%s: %+v", fhr.Address(), err)
+	}
 	matchFound := false
 
 	for _, file := range files {
+		isDir, err := afero.IsDir(fs, file)
+		if err != nil {
+			return err
+		}
+		if isDir {
+			continue
+		}
 		fileData, err := afero.ReadFile(fs, file)
 		if err != nil {
 			return err
 		}
-
-		// Calculate the hash of the file data
-		var h hash.Hash
-		switch fhr.Algorithm {
-		case "md5":
-			h = md5.New()
-		case "sha256":
-			h = sha256.New()
-		case "sha512":
-			h = sha512.New()
-		case "sha1":
-			fallthrough
-		default: // Default to sha1
-			h = sha1.New()
+		if fileData, err = fhr.Normalize.apply(fileData); err != nil {
+			return fmt.Errorf("%s: %+v", fhr.Address(), err)
 		}
-		h.Write(fileData)
-		computedHash := fmt.Sprintf("%x", h.Sum(nil))
 
-		if computedHash == fhr.Hash {
+		computedHash := fhr.hash(fileData)
+		fhr.FileHashes[file] = computedHash
+
+		if slices.Contains(expectedHashes, computedHash) {
 			matchFound = true
 			continue
 		}
@@ -80,17 +123,56 @@ func (fhr *FileHashRule) ExecuteDuringPlan() error {
 		return nil
 	}
 
-	fhr.setCheckError(fmt.Errorf("file with glob %s and  different hash than %s found", fhr.Glob, fhr.Hash))
+	fhr.setCheckError(fmt.Errorf("file with glob %s and  different hash than %s found", fhr.Glob, strings.Join(expectedHashes, ", ")))
 	return nil
 }
 
+// expectedHashes returns `hash`, `hashes`, and the hash of `expected_content`, which is normalized the same way as file content.
+func (fhr *FileHashRule) expectedHashes() ([]string, error) {
+	var hashes []string
+	if fhr.Hash != "" {
+		hashes = append(hashes, fhr.Hash)
+	}
+	hashes = append(hashes, fhr.Hashes...)
+	if fhr.ExpectedContent == "" {
+		return hashes, nil
+	}
+	content, err := fhr.Normalize.apply([]byte(fhr.ExpectedContent))
+	if err != nil {
+		return nil, err
+	}
+	return append(hashes, fhr.hash(content)), nil
+}
+
+func (fhr *FileHashRule) hash(data []byte) string {
+	var h hash.Hash
+	switch fhr.Algorithm {
+	case "md5":
+		h = md5.New()
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	case "sha1":
+		fallthrough
+	default: // Default to sha1
+		h = sha1.New()
+	}
+	h.Write(data)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func (fhr *FileHashRule) violatingFiles() []string {
+	return fhr.HashMismatchFiles
+}
+
 func (fhr *FileHashRule) Validate() error {
 	if fhr.Glob == "" {
 		return fmt.Errorf("glob is required")
 	}
 
-	if fhr.Hash == "" {
-		return fmt.Errorf("hash is required")
+	if fhr.Hash == "" && len(fhr.Hashes) == 0 && fhr.ExpectedContent == "" {
+		return fmt.Errorf("hash, hashes or expected_content is required")
 	}
 
 	if fhr.Algorithm != "" {
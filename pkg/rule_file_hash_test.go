@@ -1,15 +1,21 @@
 package pkg
 
 import (
+	"context"
 	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/Azure/golden"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"github.com/zclconf/go-cty/cty"
 )
 
 type fileHashRuleSuite struct {
@@ -290,3 +296,153 @@ func (s *fileHashRuleSuite) TestFileHashRule_FailOnHashMismatch() {
 		})
 	}
 }
+
+func (s *fileHashRuleSuite) TestFileHashRule_Normalize() {
+	license := "MIT License\n\nCopyright (c) 2023 Contoso\n\nPermission is hereby granted.\n"
+	_ = afero.WriteFile(s.fs, "/crlf/LICENSE", []byte(strings.ReplaceAll(license, "\n", "\r\n")), 0644)
+	_ = afero.WriteFile(s.fs, "/trailing/LICENSE", []byte("MIT License  \n\nCopyright (c) 2023 Contoso\n\nPermission is hereby granted.\n\n\n"), 0644)
+	_ = afero.WriteFile(s.fs, "/holder/LICENSE", []byte(strings.ReplaceAll(license, "2023 Contoso", "2024 Fabrikam")), 0644)
+	expected := fmt.Sprintf("%x", sha1.Sum([]byte("MIT License\n\n\n\nPermission is hereby granted.")))
+	cases := []struct {
+		desc      string
+		glob      string
+		normalize *FileHashNormalize
+		wantError bool
+	}{
+		{
+			desc:      "not_normalized",
+			glob:      "/crlf/LICENSE",
+			wantError: true,
+		},
+		{
+			desc: "line_endings",
+			glob: "/crlf/LICENSE",
+			normalize: &FileHashNormalize{
+				LineEndings:        true,
+				TrailingWhitespace: true,
+				Placeholders:       []string{`Copyright \(c\) .*`},
+			},
+		},
+		{
+			desc: "trailing_whitespace",
+			glob: "/trailing/LICENSE",
+			normalize: &FileHashNormalize{
+				TrailingWhitespace: true,
+				Placeholders:       []string{`Copyright \(c\) .*`},
+			},
+		},
+		{
+			desc: "placeholders",
+			glob: "/holder/LICENSE",
+			normalize: &FileHashNormalize{
+				TrailingWhitespace: true,
+				Placeholders:       []string{`Copyright \(c\) \d{4} .*`},
+			},
+		},
+		{
+			desc: "placeholders_not_match",
+			glob: "/holder/LICENSE",
+			normalize: &FileHashNormalize{
+				TrailingWhitespace: true,
+				Placeholders:       []string{`Copyright \(c\) \d{4} Contoso`},
+			},
+			wantError: true,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			rule := &FileHashRule{
+				BaseRule:  new(BaseRule),
+				Glob:      c.glob,
+				Hash:      expected,
+				Algorithm: "sha1",
+				Normalize: c.normalize,
+			}
+			s.NoError(rule.ExecuteDuringPlan())
+			if c.wantError {
+				s.Error(rule.CheckError())
+			} else {
+				s.NoError(rule.CheckError())
+			}
+		})
+	}
+}
+
+func (s *fileHashRuleSuite) TestFileHashRule_InvalidPlaceholder() {
+	_ = afero.WriteFile(s.fs, "/LICENSE", []byte("MIT"), 0644)
+	rule := &FileHashRule{
+		BaseRule:  new(BaseRule),
+		Glob:      "/LICENSE",
+		Hash:      "abc",
+		Normalize: &FileHashNormalize{Placeholders: []string{"("}},
+	}
+	s.Error(rule.ExecuteDuringPlan())
+}
+
+func (s *fileHashRuleSuite) TestFileHashRule_HashesAndExportedFileHashes() {
+	t := s.T()
+	content := `
+	rule "file_hash" license {
+		glob   = "/*/LICENSE"
+		hashes = [sha1("MIT"), sha1("Apache-2.0")]
+		normalize {
+			trailing_whitespace = true
+		}
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/a/LICENSE", "/b/LICENSE", "/c/LICENSE"}, []string{content, "MIT\n", "Apache-2.0", "BSD"})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 0)
+	rules := golden.Blocks[Rule](config)
+	require.Len(t, rules, 1)
+	rule := rules[0].(*FileHashRule)
+	s.Equal([]string{"/c/LICENSE"}, rule.HashMismatchFiles)
+	s.Equal(map[string]string{
+		"/a/LICENSE": fmt.Sprintf("%x", sha1.Sum([]byte("MIT"))),
+		"/b/LICENSE": fmt.Sprintf("%x", sha1.Sum([]byte("Apache-2.0"))),
+		"/c/LICENSE": fmt.Sprintf("%x", sha1.Sum([]byte("BSD"))),
+	}, rule.FileHashes)
+	fileHashes := golden.Value(rule)["file_hashes"]
+	s.Equal(cty.StringVal(fmt.Sprintf("%x", sha1.Sum([]byte("BSD")))), fileHashes.Index(cty.StringVal("/c/LICENSE")))
+}
+
+func (s *fileHashRuleSuite) TestFileHashRule_ExpectedContentShouldBeNormalized() {
+	t := s.T()
+	content := `
+	rule "file_hash" license {
+		glob             = "/*/LICENSE"
+		algorithm        = "sha256"
+		expected_content = "MIT License  \r\n\r\nCopyright (c) 2023 Contoso\r\n"
+		normalize {
+			line_endings        = true
+			placeholders        = ["Copyright \\(c\\) .*"]
+			trailing_whitespace = true
+		}
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/a/LICENSE", "/b/LICENSE"}, []string{content, "MIT License\n\nCopyright (c) 2024 Fabrikam\n", "Apache License\n"})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.NoError(t, err)
+	rule := golden.Blocks[Rule](config)[0].(*FileHashRule)
+	s.Equal([]string{"/b/LICENSE"}, rule.HashMismatchFiles)
+	s.Equal(fmt.Sprintf("%x", sha256.Sum256([]byte("MIT License"))), rule.FileHashes["/a/LICENSE"])
+}
+
+func (s *fileHashRuleSuite) TestFileHashRule_HashOrHashesRequired() {
+	t := s.T()
+	content := `
+	rule "file_hash" license {
+		glob = "/LICENSE"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/LICENSE"}, []string{content, "MIT"})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+)
+
+var _ Rule = &FileModeRule{}
+
+type FileModeRule struct {
+	*golden.BaseBlock
+	*BaseRule
+	Glob             string            `hcl:"glob"`
+	Exclude          []string          `hcl:"exclude,optional"`
+	RespectGitIgnore bool              `hcl:"respect_gitignore,optional"`
+	Mode             *fs.FileMode      `hcl:"mode,optional" validate:"omitempty,file_mode"`
+	Mask             *fs.FileMode      `hcl:"mask,optional" validate:"omitempty,file_mode,required_with=Mode"`
+	Executable       *bool             `hcl:"executable,optional"`
+	WorldWritable    *bool             `hcl:"world_writable,optional"`
+	Symlink          *bool             `hcl:"symlink,optional"`
+	RegularFile      *bool             `hcl:"regular_file,optional"`
+	MismatchedFiles  []string          `attribute:"mismatched_files"`
+	FileModes        map[string]string `attribute:"file_modes"`
+}
+
+func (f *FileModeRule) Type() string {
+	return "file_mode"
+}
+
+func (f *FileModeRule) ExecuteDuringPlan() error {
+	f.MismatchedFiles, f.FileModes = nil, make(map[string]string)
+	var mode, mask fs.FileMode
+	var err error
+	if f.Mode != nil {
+		if mode, err = toDecimal(*f.Mode); err != nil {
+			return fmt.Errorf("%s: %+v", f.Address(), err)
+		}
+	}
+	mask = fs.ModePerm
+	if f.Mask != nil {
+		if mask, err = toDecimal(*f.Mask); err != nil {
+			return fmt.Errorf("%s: %+v", f.Address(), err)
+		}
+	}
+	afs := FsFactory()
+	files, err := f.glob(afs, newFileWalker(f.Glob, f.Exclude, f.RespectGitIgnore))
+	if err != nil {
+		return fmt.Errorf("error on glob files %s, %s", f.Glob, f.Address())
+	}
+	if len(files) == 0 {
+		f.setCheckError(fmt.Errorf("no files match path pattern: %s", f.Glob))
+		return nil
+	}
+	var checkErr error
+	for _, file := range files {
+		info, err := lstatIfPossible(afs, file)
+		if err != nil {
+			return err
+		}
+		isSymlink := info.Mode()&fs.ModeSymlink != 0
+		if isSymlink {
+			// permissions of symlinks are meaningless, check the permissions of their targets
+			if target, err := afs.Stat(file); err == nil {
+				info = target
+			}
+		}
+		perm := info.Mode().Perm()
+		f.FileModes[file] = fmt.Sprintf("%04o", perm)
+		var violations []string
+		if f.Mode != nil && perm&mask != mode&mask {
+			if f.Mask != nil {
+				violations = append(violations, fmt.Sprintf("mode %04o with mask %04o, expected %04o", perm, mask, mode&mask))
+			} else {
+				violations = append(violations, fmt.Sprintf("mode %04o, expected %04o", perm, mode))
+			}
+		}
+		if f.Executable != nil && *f.Executable && perm&0100 == 0 {
+			violations = append(violations, "not executable by owner")
+		}
+		if f.Executable != nil && !*f.Executable && perm&0111 != 0 {
+			violations = append(violations, "executable")
+		}
+		if f.WorldWritable != nil && *f.WorldWritable != (perm&0002 != 0) {
+			violations = append(violations, fmt.Sprintf("world writable is %t", perm&0002 != 0))
+		}
+		if f.Symlink != nil && *f.Symlink != isSymlink {
+			violations = append(violations, fmt.Sprintf("symlink is %t", isSymlink))
+		}
+		if f.RegularFile != nil && *f.RegularFile != (!isSymlink && info.Mode().IsRegular()) {
+			violations = append(violations, fmt.Sprintf("regular file is %t", !isSymlink && info.Mode().IsRegular()))
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		f.MismatchedFiles = append(f.MismatchedFiles, file)
+		checkErr = multierror.Append(checkErr, fmt.Errorf("%s: %s", file, strings.Join(violations, ", ")))
+	}
+	if checkErr != nil {
+		f.setCheckError(checkErr)
+	}
+	return nil
+}
+
+func (f *FileModeRule) violatingFiles() []string {
+	return f.MismatchedFiles
+}
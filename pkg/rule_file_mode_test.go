@@ -0,0 +1,213 @@
+package pkg
+
+import (
+	"context"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Azure/golden"
+	"github.com/prashantv/gostub"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type fileModeRuleSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestFileModeRuleSuite(t *testing.T) {
+	suite.Run(t, new(fileModeRuleSuite))
+}
+
+func (s *fileModeRuleSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *fileModeRuleSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *fileModeRuleSuite) TestFileModeRule_Check() {
+	_ = afero.WriteFile(s.fs, "/scripts/build.sh", []byte(""), 0755)
+	_ = afero.WriteFile(s.fs, "/scripts/test.sh", []byte(""), 0644)
+	_ = afero.WriteFile(s.fs, "/secrets/key.pem", []byte(""), 0640)
+	_ = afero.WriteFile(s.fs, "/tmp/shared", []byte(""), 0666)
+	_ = s.fs.Chmod("/tmp/shared", 0666)
+	mode755 := iofs.FileMode(755)
+	mode600 := iofs.FileMode(600)
+	mask077 := iofs.FileMode(77)
+	mode0 := iofs.FileMode(0)
+	yes := true
+	no := false
+	cases := []struct {
+		desc           string
+		rule           *FileModeRule
+		wantError      bool
+		wantMismatched []string
+	}{
+		{
+			desc: "exact_mode",
+			rule: &FileModeRule{
+				Glob: "/scripts/*.sh",
+				Mode: &mode755,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/scripts/test.sh"},
+		},
+		{
+			desc: "executable",
+			rule: &FileModeRule{
+				Glob:       "/scripts/*.sh",
+				Executable: &yes,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/scripts/test.sh"},
+		},
+		{
+			desc: "not_executable",
+			rule: &FileModeRule{
+				Glob:       "/secrets/*",
+				Executable: &no,
+			},
+		},
+		{
+			desc: "masked_mode",
+			rule: &FileModeRule{
+				Glob: "/secrets/*",
+				Mode: &mode0,
+				Mask: &mask077,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/secrets/key.pem"},
+		},
+		{
+			desc: "masked_mode_ignores_owner_bits",
+			rule: &FileModeRule{
+				Glob: "/scripts/test.sh",
+				Mode: &mode600,
+				Mask: &mask077,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/scripts/test.sh"},
+		},
+		{
+			desc: "not_world_writable",
+			rule: &FileModeRule{
+				Glob:          "/**",
+				WorldWritable: &no,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/tmp/shared"},
+		},
+		{
+			desc: "regular_file",
+			rule: &FileModeRule{
+				Glob:        "/scripts/*",
+				RegularFile: &yes,
+			},
+		},
+		{
+			desc: "directory_is_not_regular_file",
+			rule: &FileModeRule{
+				Glob:        "/scripts",
+				RegularFile: &yes,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/scripts"},
+		},
+		{
+			desc: "no_file",
+			rule: &FileModeRule{
+				Glob:       "/bin/*",
+				Executable: &yes,
+			},
+			wantError: true,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			c.rule.BaseRule = new(BaseRule)
+			err := c.rule.ExecuteDuringPlan()
+			s.NoError(err)
+			if c.wantError {
+				s.Error(c.rule.CheckError())
+			} else {
+				s.NoError(c.rule.CheckError())
+			}
+			s.Equal(c.wantMismatched, c.rule.MismatchedFiles)
+		})
+	}
+}
+
+func (s *fileModeRuleSuite) TestFileModeRule_ExportFileModes() {
+	t := s.T()
+	content := `
+	rule "file_mode" scripts {
+		glob = "/scripts/*.sh"
+		mode = 0755
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl"}, []string{content})
+	_ = afero.WriteFile(s.fs, "/scripts/build.sh", []byte(""), 0755)
+	_ = afero.WriteFile(s.fs, "/scripts/test.sh", []byte(""), 0600)
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	s.Contains(plan.FailedRules[0].CheckError.Error(), "/scripts/test.sh: mode 0600, expected 0755")
+	rules := golden.Blocks[Rule](config)
+	require.Len(t, rules, 1)
+	fileModes := golden.Value(rules[0])["file_modes"]
+	s.Equal(cty.StringVal("0755"), fileModes.Index(cty.StringVal("/scripts/build.sh")))
+	s.Equal(cty.StringVal("0600"), fileModes.Index(cty.StringVal("/scripts/test.sh")))
+}
+
+func (s *fileModeRuleSuite) TestFileModeRule_MaskRequiresMode() {
+	t := s.T()
+	content := `
+	rule "file_mode" secrets {
+		glob = "/secrets/*"
+		mask = 0077
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/secrets/key.pem"}, []string{content, ""})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
+
+func TestFileModeRule_Symlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires privileges on Windows")
+	}
+	stub := gostub.Stub(&FsFactory, func() afero.Fs {
+		return afero.NewOsFs()
+	})
+	defer stub.Reset()
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.sh")
+	require.NoError(t, os.WriteFile(target, []byte(""), 0755))
+	require.NoError(t, os.Symlink(target, filepath.Join(dir, "link.sh")))
+	yes := true
+	rule := &FileModeRule{
+		BaseRule: new(BaseRule),
+		Glob:     filepath.Join(dir, "*.sh"),
+		Symlink:  &yes,
+	}
+	require.NoError(t, rule.ExecuteDuringPlan())
+	require.Error(t, rule.CheckError())
+	require.Equal(t, []string{filepath.ToSlash(target)}, rule.MismatchedFiles)
+	rule.Symlink = nil
+	rule.RegularFile = &yes
+	rule.Executable = &yes
+	require.NoError(t, rule.ExecuteDuringPlan())
+	require.Equal(t, []string{filepath.ToSlash(filepath.Join(dir, "link.sh"))}, rule.MismatchedFiles)
+}
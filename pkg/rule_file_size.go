@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+)
+
+var _ Rule = &FileSizeRule{}
+
+type FileSizeRule struct {
+	*golden.BaseBlock
+	*BaseRule
+	Glob                string           `hcl:"glob"`
+	Exclude             []string         `hcl:"exclude,optional"`
+	RespectGitIgnore    bool             `hcl:"respect_gitignore,optional"`
+	MaxBytes            *int64           `hcl:"max_bytes,optional" validate:"omitempty,min=0,at_least_one_of=MaxBytes MinBytes ForbidBinary"`
+	MinBytes            *int64           `hcl:"min_bytes,optional" validate:"omitempty,min=0,at_least_one_of=MaxBytes MinBytes ForbidBinary"`
+	ForbidBinary        bool             `hcl:"forbid_binary,optional" validate:"at_least_one_of=MaxBytes MinBytes ForbidBinary"`
+	MismatchedFiles     []string         `attribute:"mismatched_files"`
+	MismatchedFileSizes map[string]int64 `attribute:"mismatched_file_sizes"`
+}
+
+func (f *FileSizeRule) Type() string {
+	return "file_size"
+}
+
+func (f *FileSizeRule) ExecuteDuringPlan() error {
+	f.MismatchedFiles, f.MismatchedFileSizes = nil, make(map[string]int64)
+	fs := FsFactory()
+	files, err := f.glob(fs, newFileWalker(f.Glob, f.Exclude, f.RespectGitIgnore))
+	if err != nil {
+		return fmt.Errorf("error on glob files %s, %s", f.Glob, f.Address())
+	}
+	var checkErr error
+	for _, file := range files {
+		info, err := fs.Stat(file)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			continue
+		}
+		size := info.Size()
+		var violations []string
+		if f.MaxBytes != nil && size > *f.MaxBytes {
+			violations = append(violations, fmt.Sprintf("%d bytes, larger than %d bytes", size, *f.MaxBytes))
+		}
+		if f.MinBytes != nil && size < *f.MinBytes {
+			violations = append(violations, fmt.Sprintf("%d bytes, smaller than %d bytes", size, *f.MinBytes))
+		}
+		if f.ForbidBinary {
+			binary, err := isBinaryFile(fs, file)
+			if err != nil {
+				return err
+			}
+			if binary {
+				violations = append(violations, "binary file")
+			}
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		f.MismatchedFiles = append(f.MismatchedFiles, file)
+		f.MismatchedFileSizes[file] = size
+		checkErr = multierror.Append(checkErr, fmt.Errorf("%s: %s", file, strings.Join(violations, ", ")))
+	}
+	if checkErr != nil {
+		f.setCheckError(checkErr)
+	}
+	return nil
+}
+
+func (f *FileSizeRule) violatingFiles() []string {
+	return f.MismatchedFiles
+}
+
+// isBinaryFile sniffs the beginning of the file, a file is binary if it contains NUL bytes, or its content type is not text,
+// like images and archives. Files start with a UTF-16 byte order mark are text even though they contain NUL bytes.
+func isBinaryFile(fs afero.Fs, file string) (bool, error) {
+	f, err := fs.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	head := make([]byte, 8000)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	if !strings.HasPrefix(contentType, "text/") {
+		return true, nil
+	}
+	if bytes.HasPrefix(head, []byte{0xFE, 0xFF}) || bytes.HasPrefix(head, []byte{0xFF, 0xFE}) {
+		return false, nil
+	}
+	return isBinary(head), nil
+}
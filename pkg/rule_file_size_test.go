@@ -0,0 +1,141 @@
+package pkg
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Azure/golden"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type fileSizeRuleSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestFileSizeRuleSuite(t *testing.T) {
+	suite.Run(t, new(fileSizeRuleSuite))
+}
+
+func (s *fileSizeRuleSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *fileSizeRuleSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *fileSizeRuleSuite) TestFileSizeRule_Check() {
+	_ = afero.WriteFile(s.fs, "/README.md", []byte("# Title\n"), 0644)
+	_ = afero.WriteFile(s.fs, "/empty.txt", []byte(""), 0644)
+	_ = afero.WriteFile(s.fs, "/model.bin", []byte(strings.Repeat("model", 100)+"\x00"), 0644)
+	_ = afero.WriteFile(s.fs, "/logo.png", []byte("\x89PNG\r\n\x1a\n"), 0644)
+	_ = afero.WriteFile(s.fs, "/utf16.txt", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, 0644)
+	var max int64 = 100
+	var min int64 = 1
+	cases := []struct {
+		desc           string
+		rule           *FileSizeRule
+		wantError      bool
+		wantMismatched []string
+	}{
+		{
+			desc: "max_bytes",
+			rule: &FileSizeRule{
+				Glob:     "/*",
+				MaxBytes: &max,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/model.bin"},
+		},
+		{
+			desc: "min_bytes",
+			rule: &FileSizeRule{
+				Glob:     "/*",
+				MinBytes: &min,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/empty.txt"},
+		},
+		{
+			desc: "forbid_binary",
+			rule: &FileSizeRule{
+				Glob:         "/*",
+				ForbidBinary: true,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/logo.png", "/model.bin"},
+		},
+		{
+			desc: "no_violation",
+			rule: &FileSizeRule{
+				Glob:         "/*.md",
+				MaxBytes:     &max,
+				MinBytes:     &min,
+				ForbidBinary: true,
+			},
+		},
+		{
+			desc: "no_file",
+			rule: &FileSizeRule{
+				Glob:     "/*.zip",
+				MaxBytes: &max,
+			},
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			c.rule.BaseRule = new(BaseRule)
+			err := c.rule.ExecuteDuringPlan()
+			s.NoError(err)
+			if c.wantError {
+				s.Error(c.rule.CheckError())
+			} else {
+				s.NoError(c.rule.CheckError())
+			}
+			s.Equal(c.wantMismatched, c.rule.MismatchedFiles)
+		})
+	}
+}
+
+func (s *fileSizeRuleSuite) TestFileSizeRule_RemoveLargeFiles() {
+	t := s.T()
+	content := `
+	rule "file_size" large_files {
+		glob      = "/data/**"
+		max_bytes = 10
+	}
+
+	fix "rm_local_file" large_files {
+		rule_ids = [rule.file_size.large_files.id]
+		paths    = [for f, size in rule.file_size.large_files.mismatched_file_sizes : f if size > 20]
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/data/small.csv", "/data/medium.csv", "/data/large.csv"}, []string{content, "a,b", strings.Repeat("a", 15), strings.Repeat("a", 25)})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	s.Contains(plan.FailedRules[0].CheckError.Error(), "/data/large.csv: 25 bytes, larger than 10 bytes")
+	fixes := golden.Blocks[Fix](config)
+	require.Len(t, fixes, 1)
+	s.Equal([]string{"/data/large.csv"}, fixes[0].(*RmLocalFileFix).Paths)
+}
+
+func (s *fileSizeRuleSuite) TestFileSizeRule_AtLeastOneCheckRequired() {
+	t := s.T()
+	content := `
+	rule "file_size" large_files {
+		glob = "/**"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
@@ -0,0 +1,180 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/spf13/afero"
+)
+
+var _ Rule = &JsonSchemaRule{}
+
+type JsonSchemaRule struct {
+	*golden.BaseBlock
+	*BaseRule
+	Glob             string                `hcl:"glob"`
+	Exclude          []string              `hcl:"exclude,optional"`
+	RespectGitIgnore bool                  `hcl:"respect_gitignore,optional"`
+	Format           string                `hcl:"format,optional" validate:"omitempty,oneof=json yaml toml"`
+	Schema           string                `hcl:"schema,optional" validate:"conflict_with=SchemaFile,at_least_one_of=Schema SchemaFile"`
+	SchemaFile       string                `hcl:"schema_file,optional" validate:"conflict_with=Schema,at_least_one_of=Schema SchemaFile"`
+	MismatchedFiles  []string              `attribute:"mismatched_files"`
+	Violations       []JsonSchemaViolation `attribute:"violations"`
+}
+
+type JsonSchemaViolation struct {
+	File             string `attribute:"file"`
+	InstanceLocation string `attribute:"instance_location"`
+	SchemaLocation   string `attribute:"schema_location"`
+	Message          string `attribute:"message"`
+}
+
+func (v JsonSchemaViolation) Error() string {
+	instance := v.InstanceLocation
+	if instance == "" {
+		instance = "/"
+	}
+	return fmt.Sprintf("%s: %s %s (schema %s)", v.File, instance, v.Message, v.SchemaLocation)
+}
+
+func (j *JsonSchemaRule) Type() string {
+	return "json_schema"
+}
+
+func (j *JsonSchemaRule) ExecuteDuringPlan() error {
+	j.MismatchedFiles, j.Violations = nil, nil
+	fs := FsFactory()
+	schema, err := j.compile(fs)
+	if err != nil {
+		return err
+	}
+	files, err := j.glob(fs, newFileWalker(j.Glob, j.Exclude, j.RespectGitIgnore))
+	if err != nil {
+		return fmt.Errorf("error on glob files %s, %s", j.Glob, j.Address())
+	}
+	if len(files) == 0 {
+		j.setCheckError(fmt.Errorf("no files match path pattern: %s", j.Glob))
+		return nil
+	}
+	var checkErr error
+	for _, file := range files {
+		isDir, err := afero.IsDir(fs, file)
+		if err != nil {
+			return err
+		}
+		if isDir {
+			continue
+		}
+		violations, err := j.validate(fs, schema, file)
+		if err != nil {
+			j.MismatchedFiles = append(j.MismatchedFiles, file)
+			checkErr = multierror.Append(checkErr, err)
+			continue
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		j.MismatchedFiles = append(j.MismatchedFiles, file)
+		j.Violations = append(j.Violations, violations...)
+		for _, v := range violations {
+			checkErr = multierror.Append(checkErr, v)
+		}
+	}
+	if checkErr != nil {
+		j.setCheckError(checkErr)
+	}
+	return nil
+}
+
+func (j *JsonSchemaRule) violatingFiles() []string {
+	return j.MismatchedFiles
+}
+
+func (j *JsonSchemaRule) compile(fs afero.Fs) (*jsonschema.Schema, error) {
+	url := "schema.json"
+	schema := []byte(j.Schema)
+	if j.SchemaFile != "" {
+		url = j.SchemaFile
+		j.recordInput(newFileWalker(j.SchemaFile, nil, false))
+		content, err := afero.ReadFile(fs, j.SchemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("error on reading schema file %s, %s: %+v", j.SchemaFile, j.Address(), err)
+		}
+		schema = content
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, bytes.NewReader(schema)); err != nil {
+		return nil, fmt.Errorf("invalid schema, %s: %+v", j.Address(), err)
+	}
+	s, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema, %s: %+v", j.Address(), err)
+	}
+	return s, nil
+}
+
+// validate returns all violations of the file, the returned error means the file cannot be parsed.
+func (j *JsonSchemaRule) validate(fs afero.Fs, schema *jsonschema.Schema, file string) ([]JsonSchemaViolation, error) {
+	root, err := parseStructuredFile(fs, file, j.Format)
+	if err != nil {
+		return nil, err
+	}
+	// convert the document to json values, like what json.Unmarshal returns, which is what the validator expects
+	var doc any
+	if len(root.Content) > 0 {
+		if err = root.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("error on reading %s: %+v", file, err)
+		}
+	}
+	j1, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("error on reading %s: %+v", file, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(j1))
+	decoder.UseNumber()
+	if err = decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error on reading %s: %+v", file, err)
+	}
+	err = schema.Validate(doc)
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return nil, err
+	}
+	var violations []JsonSchemaViolation
+	for _, leaf := range leafValidationErrors(ve) {
+		violations = append(violations, JsonSchemaViolation{
+			File:             file,
+			InstanceLocation: leaf.InstanceLocation,
+			SchemaLocation:   schemaLocation(leaf),
+			Message:          leaf.Message,
+		})
+	}
+	return violations, nil
+}
+
+// leafValidationErrors returns the innermost errors, which describe each violation, their parents only say that a sub schema doesn't validate.
+func leafValidationErrors(ve *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(ve.Causes) == 0 {
+		return []*jsonschema.ValidationError{ve}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, c := range ve.Causes {
+		leaves = append(leaves, leafValidationErrors(c)...)
+	}
+	return leaves
+}
+
+// schemaLocation returns the location of the keyword in the schema like `#/properties/version/type`, references are resolved.
+func schemaLocation(ve *jsonschema.ValidationError) string {
+	location := ve.AbsoluteKeywordLocation
+	if i := strings.Index(location, "#"); i >= 0 {
+		return location[i:]
+	}
+	return "#" + ve.KeywordLocation
+}
@@ -0,0 +1,194 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/golden"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type jsonSchemaRuleSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestJsonSchemaRuleSuite(t *testing.T) {
+	suite.Run(t, new(jsonSchemaRuleSuite))
+}
+
+func (s *jsonSchemaRuleSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *jsonSchemaRuleSuite) TearDownTest() {
+	s.teardown()
+}
+
+const dependabotSchema = `{
+	"type": "object",
+	"required": ["version", "updates"],
+	"properties": {
+		"version": {"const": 2},
+		"updates": {
+			"type": "array",
+			"items": {"$ref": "#/$defs/update"}
+		}
+	},
+	"$defs": {
+		"update": {
+			"type": "object",
+			"required": ["package-ecosystem"],
+			"properties": {
+				"package-ecosystem": {"enum": ["gomod", "github-actions"]}
+			}
+		}
+	}
+}`
+
+func (s *jsonSchemaRuleSuite) TestJsonSchemaRule_Check() {
+	s.dummyFsWithFiles([]string{"/schemas/dependabot.json", "/valid/dependabot.yml", "/invalid/dependabot.yml", "/broken/dependabot.yml"}, []string{
+		dependabotSchema,
+		"version: 2\nupdates:\n  - package-ecosystem: gomod\n",
+		"version: 1\nupdates:\n  - package-ecosystem: npm\n  - directory: /\n",
+		"version: [\n",
+	})
+	cases := []struct {
+		desc           string
+		rule           *JsonSchemaRule
+		wantError      bool
+		wantMismatched []string
+		wantViolations []JsonSchemaViolation
+	}{
+		{
+			desc: "valid_inline_schema",
+			rule: &JsonSchemaRule{
+				Glob:   "/valid/dependabot.yml",
+				Schema: dependabotSchema,
+			},
+		},
+		{
+			desc: "invalid_schema_file",
+			rule: &JsonSchemaRule{
+				Glob:       "/invalid/dependabot.yml",
+				SchemaFile: "/schemas/dependabot.json",
+			},
+			wantError:      true,
+			wantMismatched: []string{"/invalid/dependabot.yml"},
+			wantViolations: []JsonSchemaViolation{
+				{
+					File:             "/invalid/dependabot.yml",
+					InstanceLocation: "/updates/0/package-ecosystem",
+					SchemaLocation:   "#/$defs/update/properties/package-ecosystem/enum",
+				},
+				{
+					File:             "/invalid/dependabot.yml",
+					InstanceLocation: "/updates/1",
+					SchemaLocation:   "#/$defs/update/required",
+				},
+				{
+					File:             "/invalid/dependabot.yml",
+					InstanceLocation: "/version",
+					SchemaLocation:   "#/properties/version/const",
+				},
+			},
+		},
+		{
+			desc: "unparsable_file",
+			rule: &JsonSchemaRule{
+				Glob:   "/broken/dependabot.yml",
+				Schema: dependabotSchema,
+			},
+			wantError:      true,
+			wantMismatched: []string{"/broken/dependabot.yml"},
+		},
+		{
+			desc: "no_file",
+			rule: &JsonSchemaRule{
+				Glob:   "/.github/dependabot.yml",
+				Schema: dependabotSchema,
+			},
+			wantError: true,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			c.rule.BaseRule = new(BaseRule)
+			err := c.rule.ExecuteDuringPlan()
+			s.NoError(err)
+			if c.wantError {
+				s.Error(c.rule.CheckError())
+			} else {
+				s.NoError(c.rule.CheckError())
+			}
+			s.Equal(c.wantMismatched, c.rule.MismatchedFiles)
+			s.Len(c.rule.Violations, len(c.wantViolations))
+			for _, want := range c.wantViolations {
+				found := false
+				for _, v := range c.rule.Violations {
+					if v.File == want.File && v.InstanceLocation == want.InstanceLocation && v.SchemaLocation == want.SchemaLocation {
+						found = true
+						s.NotEmpty(v.Message)
+					}
+				}
+				s.True(found, "%+v not found in %+v", want, c.rule.Violations)
+			}
+		})
+	}
+}
+
+func (s *jsonSchemaRuleSuite) TestJsonSchemaRule_InvalidSchema() {
+	s.dummyFsWithFiles([]string{"/renovate.json"}, []string{`{}`})
+	rule := &JsonSchemaRule{
+		BaseRule: new(BaseRule),
+		Glob:     "/renovate.json",
+		Schema:   `{"type": 1}`,
+	}
+	s.Error(rule.ExecuteDuringPlan())
+	rule.Schema = ""
+	rule.SchemaFile = "/schemas/renovate.json"
+	s.Error(rule.ExecuteDuringPlan())
+}
+
+func (s *jsonSchemaRuleSuite) TestJsonSchemaRule_Config() {
+	t := s.T()
+	content := `
+	rule "json_schema" renovate {
+		glob   = "/renovate.json"
+		schema = jsonencode({
+			type     = "object"
+			required = ["extends"]
+		})
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/renovate.json"}, []string{content, `{"automerge": true}`})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	s.Contains(plan.FailedRules[0].CheckError.Error(), "/renovate.json: / missing properties: 'extends' (schema #/required)")
+	rules := golden.Blocks[Rule](config)
+	require.Len(t, rules, 1)
+	violations := golden.Value(rules[0])["violations"]
+	s.Equal(1, violations.LengthInt())
+	s.Equal(cty.StringVal("#/required"), violations.Index(cty.NumberIntVal(0)).GetAttr("schema_location"))
+}
+
+func (s *jsonSchemaRuleSuite) TestJsonSchemaRule_SchemaAndSchemaFileConflict() {
+	t := s.T()
+	content := `
+	rule "json_schema" renovate {
+		glob        = "/renovate.json"
+		schema      = "{}"
+		schema_file = "/renovate.schema.json"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
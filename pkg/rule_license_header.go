@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+)
+
+var _ Rule = &LicenseHeaderRule{}
+
+type LicenseHeaderRule struct {
+	*golden.BaseBlock
+	*BaseRule
+	Glob             string         `hcl:"glob"`
+	Exclude          []string       `hcl:"exclude,optional"`
+	RespectGitIgnore bool           `hcl:"respect_gitignore,optional"`
+	Header           string         `hcl:"header" validate:"required"`
+	CommentStyles    []CommentStyle `hcl:"comment_style,block"`
+	MismatchedFiles  []string       `attribute:"mismatched_files"`
+}
+
+func (l *LicenseHeaderRule) Type() string {
+	return "license_header"
+}
+
+func (l *LicenseHeaderRule) ExecuteDuringPlan() error {
+	l.MismatchedFiles = nil
+	header, err := newLicenseHeader(l.Address(), l.Header, l.CommentStyles)
+	if err != nil {
+		return err
+	}
+	fs := FsFactory()
+	files, err := l.glob(fs, newFileWalker(l.Glob, l.Exclude, l.RespectGitIgnore))
+	if err != nil {
+		return fmt.Errorf("error on glob files %s, %s", l.Glob, l.Address())
+	}
+	var checkErr error
+	for _, file := range files {
+		isDir, err := afero.IsDir(fs, file)
+		if err != nil {
+			return err
+		}
+		if isDir {
+			continue
+		}
+		binary, err := isBinaryFile(fs, file)
+		if err != nil {
+			return err
+		}
+		if binary {
+			continue
+		}
+		content, err := afero.ReadFile(fs, file)
+		if err != nil {
+			return err
+		}
+		violation, err := header.check(file, content)
+		if err != nil {
+			return fmt.Errorf("%s: %+v", l.Address(), err)
+		}
+		if violation == "" {
+			continue
+		}
+		l.MismatchedFiles = append(l.MismatchedFiles, file)
+		checkErr = multierror.Append(checkErr, fmt.Errorf("%s: %s", file, violation))
+	}
+	if checkErr != nil {
+		l.setCheckError(checkErr)
+	}
+	return nil
+}
+
+func (l *LicenseHeaderRule) violatingFiles() []string {
+	return l.MismatchedFiles
+}
@@ -0,0 +1,106 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type licenseHeaderRuleSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestLicenseHeaderRuleSuite(t *testing.T) {
+	suite.Run(t, new(licenseHeaderRuleSuite))
+}
+
+func (s *licenseHeaderRuleSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *licenseHeaderRuleSuite) TearDownTest() {
+	s.teardown()
+}
+
+const testLicenseHeader = "Copyright (c) {{year}} Contoso Ltd.\nSPDX-License-Identifier: MIT\n"
+
+func (s *licenseHeaderRuleSuite) TestLicenseHeaderRule_Check() {
+	s.dummyFsWithFiles([]string{
+		"/src/main.go",
+		"/src/build_linux.go",
+		"/src/tags_first.go",
+		"/src/missing.go",
+		"/src/outdated.go",
+		"/src/main.tf",
+		"/src/script.py",
+		"/src/encoding.py",
+		"/src/style.css",
+		"/src/logo.png",
+	}, []string{
+		"// Copyright (c) 2024 Contoso Ltd.\n// SPDX-License-Identifier: MIT\n\npackage main\n",
+		"// Copyright (c) 2020-2024 Contoso Ltd.\n// SPDX-License-Identifier: MIT\n\n//go:build linux\n\npackage main\n",
+		"//go:build linux\n\n// Copyright (c) 2024 Contoso Ltd.\n// SPDX-License-Identifier: MIT\n\npackage main\n",
+		"package main\n",
+		"// Copyright (c) 2019 Contoso Ltd.\n// SPDX-License-Identifier: Apache-2.0\n\npackage main\n",
+		"# Copyright (c) 2024 Contoso Ltd.\n# SPDX-License-Identifier: MIT\n\nresource \"null_resource\" \"this\" {}\n",
+		"#!/usr/bin/env python\n# Copyright (c) 2024 Contoso Ltd.\n# SPDX-License-Identifier: MIT\n",
+		"#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n# Copyright (c) 2024 Contoso Ltd.\n# SPDX-License-Identifier: MIT\n",
+		"/*\n * Copyright (c) 2024 Contoso Ltd.\n * SPDX-License-Identifier: MIT\n */\nbody {}\n",
+		"\x89PNG\r\n\x1a\n",
+	})
+	rule := &LicenseHeaderRule{
+		BaseRule: new(BaseRule),
+		Glob:     "/src/*",
+		Header:   testLicenseHeader,
+	}
+	s.NoError(rule.ExecuteDuringPlan())
+	s.Equal([]string{"/src/missing.go", "/src/outdated.go"}, rule.MismatchedFiles)
+	checkErr := rule.CheckError()
+	s.Error(checkErr)
+	s.Contains(checkErr.Error(), "/src/missing.go: missing license header")
+	s.Contains(checkErr.Error(), "/src/outdated.go: license header is outdated")
+}
+
+func (s *licenseHeaderRuleSuite) TestLicenseHeaderRule_CustomCommentStyle() {
+	t := s.T()
+	content := `
+	rule "license_header" source {
+		glob   = "/src/*"
+		header = "Copyright (c) {{year}} Contoso Ltd."
+		comment_style {
+			extensions  = [".go"]
+			start       = "/*"
+			end         = "*/"
+		}
+		comment_style {
+			extensions  = ["Jenkinsfile"]
+			line_prefix = "//"
+		}
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/src/main.go", "/src/Jenkinsfile"}, []string{
+		content,
+		"/*\nCopyright (c) 2024 Contoso Ltd.\n*/\npackage main\n",
+		"// Copyright (c) 2024 Contoso Ltd.\npipeline {}\n",
+	})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	s.Empty(plan.FailedRules)
+}
+
+func (s *licenseHeaderRuleSuite) TestLicenseHeaderRule_UnknownExtension() {
+	s.dummyFsWithFiles([]string{"/src/data.unknown"}, []string{"content"})
+	rule := &LicenseHeaderRule{
+		BaseRule: new(BaseRule),
+		Glob:     "/src/*",
+		Header:   testLicenseHeader,
+	}
+	err := rule.ExecuteDuringPlan()
+	s.Error(err)
+	s.Contains(err.Error(), "no comment style for /src/data.unknown")
+}
@@ -0,0 +1,316 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+)
+
+var _ Rule = &NoSecretsRule{}
+var _ findingsRule = &NoSecretsRule{}
+var _ violationsRule = &NoSecretsRule{}
+
+const (
+	highEntropyFindingRule  = "high_entropy_string"
+	defaultEntropyThreshold = 4.5
+	snippetContext          = 40
+)
+
+// builtinSecretPatterns detect well known tokens, if a pattern has a capture group, the group is the secret.
+var builtinSecretPatterns = []SecretPattern{
+	{Name: "aws_access_key_id", Regex: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "aws_secret_access_key", Regex: `(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`},
+	{Name: "azure_storage_account_key", Regex: `(?i)AccountKey=([A-Za-z0-9+/]{86}==)`},
+	{Name: "github_pat", Regex: `\b(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}\b`},
+	{Name: "github_fine_grained_pat", Regex: `\bgithub_pat_[A-Za-z0-9_]{82}\b`},
+	{Name: "private_key", Regex: `-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`},
+}
+
+// entropyCandidateRegex matches base64 and url-safe base64 like tokens, which are checked for entropy.
+var entropyCandidateRegex = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+type NoSecretsRule struct {
+	*golden.BaseBlock
+	*BaseRule
+	Glob                   string          `hcl:"glob"`
+	Exclude                []string        `hcl:"exclude,optional"`
+	RespectGitIgnore       *bool           `hcl:"respect_gitignore,optional"`
+	DisableBuiltinPatterns bool            `hcl:"disable_builtin_patterns,optional"`
+	Patterns               []SecretPattern `hcl:"pattern,block"`
+	EntropyThreshold       *float64        `hcl:"entropy_threshold,optional" validate:"omitempty,min=0"`
+	EntropyMinLength       int             `hcl:"entropy_min_length,optional" default:"20" validate:"min=1"`
+	MismatchedFiles        []string        `attribute:"mismatched_files"`
+	Findings               []SecretFinding `attribute:"findings"`
+	suppressed             map[string]bool
+}
+
+type SecretPattern struct {
+	Name  string `hcl:"name"`
+	Regex string `hcl:"regex"`
+}
+
+type SecretFinding struct {
+	File        string `attribute:"file"`
+	Line        int    `attribute:"line"`
+	Rule        string `attribute:"rule"`
+	Snippet     string `attribute:"snippet"`
+	Fingerprint string `attribute:"fingerprint"`
+}
+
+func (f SecretFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s `%s`, fingerprint %s", f.File, f.Line, f.Rule, f.Snippet, f.Fingerprint)
+}
+
+type secretDetector struct {
+	name  string
+	regex *regexp.Regexp
+}
+
+func (n *NoSecretsRule) Type() string {
+	return "no_secrets"
+}
+
+func (n *NoSecretsRule) ExecuteDuringPlan() error {
+	n.MismatchedFiles, n.Findings = nil, nil
+	detectors, err := n.detectors()
+	if err != nil {
+		return fmt.Errorf("%s: %+v", n.Address(), err)
+	}
+	respectGitIgnore := n.RespectGitIgnore == nil || *n.RespectGitIgnore
+	fs := FsFactory()
+	files, err := n.glob(fs, newFileWalker(n.Glob, n.Exclude, respectGitIgnore))
+	if err != nil {
+		return fmt.Errorf("error on glob files %s, %s", n.Glob, n.Address())
+	}
+	for _, file := range files {
+		isDir, err := afero.IsDir(fs, file)
+		if err != nil {
+			return err
+		}
+		if isDir {
+			continue
+		}
+		binary, err := isBinaryFile(fs, file)
+		if err != nil {
+			return err
+		}
+		if binary {
+			continue
+		}
+		content, err := afero.ReadFile(fs, file)
+		if err != nil {
+			return err
+		}
+		findings := n.scan(file, content, detectors)
+		if len(findings) == 0 {
+			continue
+		}
+		n.MismatchedFiles = append(n.MismatchedFiles, file)
+		n.Findings = append(n.Findings, findings...)
+	}
+	n.suppressFindings(nil)
+	return nil
+}
+
+func (n *NoSecretsRule) violatingFiles() []string {
+	return n.MismatchedFiles
+}
+
+// violations returns findings that are not suppressed, keyed by their fingerprints.
+func (n *NoSecretsRule) violations() []baselineViolation {
+	var violations []baselineViolation
+	for _, f := range n.Findings {
+		if n.suppressed[f.Fingerprint] {
+			continue
+		}
+		violations = append(violations, baselineViolation{
+			File: f.File,
+			Key:  f.Fingerprint,
+		})
+	}
+	return violations
+}
+
+func (n *NoSecretsRule) suppressFindings(fingerprints map[string]bool) {
+	n.suppressed = fingerprints
+	var checkErr error
+	for _, f := range n.Findings {
+		if fingerprints[f.Fingerprint] {
+			continue
+		}
+		checkErr = multierror.Append(checkErr, errors.New(f.String()))
+	}
+	n.setCheckError(checkErr)
+}
+
+func (n *NoSecretsRule) detectors() ([]secretDetector, error) {
+	patterns := n.Patterns
+	if !n.DisableBuiltinPatterns {
+		patterns = append(append([]SecretPattern{}, builtinSecretPatterns...), patterns...)
+	}
+	var detectors []secretDetector
+	for _, p := range patterns {
+		r, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex of pattern %s: %+v", p.Name, err)
+		}
+		detectors = append(detectors, secretDetector{
+			name:  p.Name,
+			regex: r,
+		})
+	}
+	return detectors, nil
+}
+
+func (n *NoSecretsRule) entropyThreshold() float64 {
+	if n.EntropyThreshold == nil {
+		return defaultEntropyThreshold
+	}
+	return *n.EntropyThreshold
+}
+
+// scan returns findings of patterns first, then high entropy strings that are not found by patterns.
+func (n *NoSecretsRule) scan(file string, content []byte, detectors []secretDetector) []SecretFinding {
+	var findings []SecretFinding
+	for i, line := range splitTextLines(content) {
+		text := string(line.content)
+		var matches []secretMatch
+		var found [][]int
+		for _, d := range detectors {
+			for _, m := range d.regex.FindAllStringSubmatchIndex(text, -1) {
+				start, end := m[0], m[1]
+				if len(m) >= 4 && m[2] >= 0 {
+					start, end = m[2], m[3]
+				}
+				found = append(found, []int{start, end})
+				matches = append(matches, secretMatch{rule: d.name, start: start, end: end})
+			}
+		}
+		if threshold := n.entropyThreshold(); threshold > 0 {
+			for _, m := range entropyCandidateRegex.FindAllStringIndex(text, -1) {
+				token := text[m[0]:m[1]]
+				if len(token) < n.EntropyMinLength || overlapped(found, m[0], m[1]) || !strings.ContainsAny(token, "0123456789") {
+					continue
+				}
+				if shannonEntropy(token) < threshold {
+					continue
+				}
+				matches = append(matches, secretMatch{rule: highEntropyFindingRule, start: m[0], end: m[1]})
+			}
+		}
+		// all secrets of the line are collected first, so none of them leaks through the snippet of another finding
+		secrets := make([][]int, 0, len(matches))
+		for _, m := range matches {
+			secrets = append(secrets, []int{m.start, m.end})
+		}
+		for _, m := range matches {
+			findings = append(findings, newSecretFinding(file, i+1, m.rule, text, m.start, m.end, secrets))
+		}
+	}
+	return findings
+}
+
+type secretMatch struct {
+	rule       string
+	start, end int
+}
+
+// newSecretFinding returns a finding with a snippet of the line that has all secrets of the line redacted, its fingerprint is computed from the file,
+// the rule and the secret, so it doesn't change when the line moves.
+func newSecretFinding(file string, line int, rule, text string, start, end int, secrets [][]int) SecretFinding {
+	secret := text[start:end]
+	from, to := max(start-snippetContext, 0), min(end+snippetContext, len(text))
+	snippet := redactRanges(text, from, to, secrets)
+	if from > 0 {
+		snippet = "..." + snippet
+	}
+	if to < len(text) {
+		snippet += "..."
+	}
+	return SecretFinding{
+		File:        file,
+		Line:        line,
+		Rule:        rule,
+		Snippet:     strings.ToValidUTF8(strings.TrimSpace(snippet), ""),
+		Fingerprint: fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s", file, rule, secret)))),
+	}
+}
+
+// redactRanges returns text[from:to] with every range of secrets in it redacted, secrets cut by the window's edges are fully masked.
+func redactRanges(text string, from, to int, secrets [][]int) string {
+	var sb strings.Builder
+	pos := from
+	for _, r := range mergeRanges(secrets) {
+		start, end := max(r[0], from), min(r[1], to)
+		if start >= end {
+			continue
+		}
+		sb.WriteString(text[pos:start])
+		if start == r[0] && end == r[1] {
+			sb.WriteString(redactSecret(text[start:end]))
+		} else {
+			sb.WriteString("****")
+		}
+		pos = end
+	}
+	sb.WriteString(text[pos:to])
+	return sb.String()
+}
+
+// mergeRanges returns sorted ranges with overlapped ones merged.
+func mergeRanges(ranges [][]int) [][]int {
+	sorted := append([][]int{}, ranges...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i][0] < sorted[j][0]
+	})
+	var merged [][]int
+	for _, r := range sorted {
+		if last := len(merged) - 1; last >= 0 && r[0] < merged[last][1] {
+			merged[last] = []int{merged[last][0], max(merged[last][1], r[1])}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// redactSecret keeps the first 4 characters of long secrets, so the secret could be recognized without being leaked.
+func redactSecret(secret string) string {
+	if len(secret) < 16 {
+		return "****"
+	}
+	return secret[:4] + "****"
+}
+
+func overlapped(ranges [][]int, start, end int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the entropy of the string in bits per character.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,262 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/ahmetb/go-linq/v3"
+	"github.com/hashicorp/go-multierror"
+	yaml "github.com/lonegunmanb/atomatt-yaml"
+	yptr "github.com/lonegunmanb/yaml-jsonpointer"
+	"github.com/spf13/afero"
+)
+
+var _ Rule = &StructuredValueRule{}
+
+type StructuredValueRule struct {
+	*golden.BaseBlock
+	*BaseRule
+	Glob             string                     `hcl:"glob"`
+	Exclude          []string                   `hcl:"exclude,optional"`
+	RespectGitIgnore bool                       `hcl:"respect_gitignore,optional"`
+	Format           string                     `hcl:"format,optional" validate:"omitempty,oneof=json yaml toml"`
+	Assertions       []StructuredValueAssertion `hcl:"assertion,block" validate:"min=1"`
+	MismatchedFiles  []string                   `attribute:"mismatched_files"`
+	Values           []StructuredValue          `attribute:"values"`
+}
+
+type StructuredValueAssertion struct {
+	Path   string   `hcl:"path"`
+	Absent bool     `hcl:"absent,optional"`
+	Equals *string  `hcl:"equals,optional"`
+	Regex  *string  `hcl:"regex,optional"`
+	OneOf  []string `hcl:"one_of,optional"`
+}
+
+type StructuredValue struct {
+	File   string `attribute:"file"`
+	Path   string `attribute:"path"`
+	Exists bool   `attribute:"exists"`
+	Value  string `attribute:"value"`
+}
+
+func (s *StructuredValueRule) Type() string {
+	return "structured_value"
+}
+
+func (s *StructuredValueRule) ExecuteDuringPlan() error {
+	pointers, err := s.pointers()
+	if err != nil {
+		return err
+	}
+	s.MismatchedFiles, s.Values = nil, nil
+	fs := FsFactory()
+	files, err := s.glob(fs, newFileWalker(s.Glob, s.Exclude, s.RespectGitIgnore))
+	if err != nil {
+		return fmt.Errorf("error on glob files %s, %s", s.Glob, s.Address())
+	}
+	if len(files) == 0 {
+		s.setCheckError(fmt.Errorf("no files match path pattern: %s", s.Glob))
+		return nil
+	}
+	var checkErr error
+	for _, file := range files {
+		isDir, err := afero.IsDir(fs, file)
+		if err != nil {
+			return err
+		}
+		if isDir {
+			continue
+		}
+		root, err := parseStructuredFile(fs, file, s.Format)
+		if err != nil {
+			s.MismatchedFiles = append(s.MismatchedFiles, file)
+			checkErr = multierror.Append(checkErr, err)
+			continue
+		}
+		mismatched := false
+		for i, a := range s.Assertions {
+			values, err := s.find(file, root, a.Path, pointers[i])
+			if err != nil {
+				return err
+			}
+			s.Values = append(s.Values, values...)
+			if err = a.check(values); err != nil {
+				mismatched = true
+				checkErr = multierror.Append(checkErr, fmt.Errorf("%s: %+v", file, err))
+			}
+		}
+		if mismatched {
+			s.MismatchedFiles = append(s.MismatchedFiles, file)
+		}
+	}
+	if checkErr != nil {
+		s.setCheckError(checkErr)
+	}
+	return nil
+}
+
+func (s *StructuredValueRule) violatingFiles() []string {
+	return s.MismatchedFiles
+}
+
+// pointers converts paths of all assertions to JSON Pointers, and compiles regexes, so invalid config is reported before any file is read.
+func (s *StructuredValueRule) pointers() ([]string, error) {
+	var pointers []string
+	for _, a := range s.Assertions {
+		if err := a.validate(); err != nil {
+			return nil, fmt.Errorf("invalid assertion on %s, %s: %+v", a.Path, s.Address(), err)
+		}
+		p, err := jsonPointer(a.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s, %s: %+v", a.Path, s.Address(), err)
+		}
+		pointers = append(pointers, p)
+	}
+	return pointers, nil
+}
+
+// find returns all values that the pointer points to, a path that contains filters like `~{"name":"build"}` could match more than one value.
+// If no value is found, a value that doesn't exist is returned.
+func (s *StructuredValueRule) find(file string, root *yaml.Node, path, pointer string) ([]StructuredValue, error) {
+	notExist := []StructuredValue{{File: file, Path: path}}
+	if len(root.Content) == 0 {
+		return notExist, nil
+	}
+	nodes, err := yptr.FindAll(root, pointer)
+	if err != nil || len(nodes) == 0 {
+		return notExist, nil
+	}
+	var values []StructuredValue
+	for _, n := range nodes {
+		value, err := nodeValue(n)
+		if err != nil {
+			return nil, fmt.Errorf("error on reading %s in %s, %s: %+v", path, file, s.Address(), err)
+		}
+		values = append(values, StructuredValue{
+			File:   file,
+			Path:   path,
+			Exists: true,
+			Value:  value,
+		})
+	}
+	return values, nil
+}
+
+// nodeValue returns the value of a scalar node, mappings and sequences are encoded as json.
+func nodeValue(n *yaml.Node) (string, error) {
+	if n.Kind == yaml.ScalarNode {
+		if n.Tag == "!!null" {
+			return "null", nil
+		}
+		return n.Value, nil
+	}
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return "", err
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(j), nil
+}
+
+func (a StructuredValueAssertion) validate() error {
+	set := 0
+	if a.Equals != nil {
+		set++
+	}
+	if a.Regex != nil {
+		set++
+		if _, err := regexp.Compile(*a.Regex); err != nil {
+			return err
+		}
+	}
+	if a.OneOf != nil {
+		set++
+	}
+	if set > 1 {
+		return fmt.Errorf("only one of `equals`, `regex` and `one_of` could be set")
+	}
+	if a.Absent && set > 0 {
+		return fmt.Errorf("`absent` cannot be used with `equals`, `regex` or `one_of`")
+	}
+	return nil
+}
+
+func (a StructuredValueAssertion) check(values []StructuredValue) error {
+	if a.Absent {
+		if values[0].Exists {
+			return fmt.Errorf("%s should be absent, got %s", a.Path, strconv.Quote(values[0].Value))
+		}
+		return nil
+	}
+	if !values[0].Exists {
+		return fmt.Errorf("%s not found", a.Path)
+	}
+	for _, v := range values {
+		switch {
+		case a.Equals != nil && v.Value != *a.Equals:
+			return fmt.Errorf("%s is %s, expected %s", a.Path, strconv.Quote(v.Value), strconv.Quote(*a.Equals))
+		case a.Regex != nil && !regexp.MustCompile(*a.Regex).MatchString(v.Value):
+			return fmt.Errorf("%s is %s, expected to match %s", a.Path, strconv.Quote(v.Value), *a.Regex)
+		case a.OneOf != nil && !linq.From(a.OneOf).Contains(v.Value):
+			return fmt.Errorf("%s is %s, expected one of %s", a.Path, strconv.Quote(v.Value), strings.Join(a.OneOf, ", "))
+		}
+	}
+	return nil
+}
+
+// jsonPointer converts a simple JSONPath like `$.jobs.build.steps[0]` or `$['a.b']` to a JSON Pointer, JSON Pointers are returned as is.
+func jsonPointer(path string) (string, error) {
+	if strings.HasPrefix(path, "/") {
+		return path, nil
+	}
+	if !strings.HasPrefix(path, "$") {
+		return "", fmt.Errorf("path must be a JSON Pointer that starts with `/` or a JSONPath that starts with `$`")
+	}
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	sb := strings.Builder{}
+	rest := path[1:]
+	for rest != "" {
+		var token string
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			token, rest = rest[1:end+1], rest[end+1:]
+		case strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, `["`):
+			end := strings.Index(rest[2:], string(rest[1])+"]")
+			if end < 0 {
+				return "", fmt.Errorf("unclosed bracket in %s", path)
+			}
+			token, rest = rest[2:end+2], rest[end+4:]
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return "", fmt.Errorf("unclosed bracket in %s", path)
+			}
+			token, rest = rest[1:end], rest[end+1:]
+			if _, err := strconv.Atoi(token); err != nil {
+				return "", fmt.Errorf("only index and quoted name are supported in brackets, got %s in %s", token, path)
+			}
+		default:
+			return "", fmt.Errorf("unexpected %s in %s", rest, path)
+		}
+		if token == "" || token == "*" {
+			return "", fmt.Errorf("empty name and wildcard are not supported in %s", path)
+		}
+		sb.WriteString("/" + escaper.Replace(token))
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("path %s points to the whole document", path)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,207 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/golden"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type structuredValueRuleSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestStructuredValueRuleSuite(t *testing.T) {
+	suite.Run(t, new(structuredValueRuleSuite))
+}
+
+func (s *structuredValueRuleSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *structuredValueRuleSuite) TearDownTest() {
+	s.teardown()
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func (s *structuredValueRuleSuite) TestStructuredValueRule_Check() {
+	s.dummyFsWithFiles([]string{"/package.json", "/.github/workflows/ci.yml", "/pyproject.toml"}, []string{
+		"{\n\t\"name\": \"app\",\n\t\"engines\": {\"node\": \"18\"},\n\t\"private\": true\n}\n",
+		"jobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - name: test\n        run: make test\n",
+		"[project]\nname = \"app\"\nrequires-python = \">=3.9\"\n",
+	})
+	cases := []struct {
+		desc           string
+		glob           string
+		assertion      StructuredValueAssertion
+		wantError      bool
+		wantMismatched []string
+	}{
+		{
+			desc:      "json_equals",
+			glob:      "/package.json",
+			assertion: StructuredValueAssertion{Path: "/engines/node", Equals: strPtr("18")},
+		},
+		{
+			desc:           "json_not_equals",
+			glob:           "/package.json",
+			assertion:      StructuredValueAssertion{Path: "$.engines.node", Equals: strPtr("20")},
+			wantError:      true,
+			wantMismatched: []string{"/package.json"},
+		},
+		{
+			desc:      "json_exists",
+			glob:      "/package.json",
+			assertion: StructuredValueAssertion{Path: "/private"},
+		},
+		{
+			desc:           "json_not_exists",
+			glob:           "/package.json",
+			assertion:      StructuredValueAssertion{Path: "/license"},
+			wantError:      true,
+			wantMismatched: []string{"/package.json"},
+		},
+		{
+			desc:      "yaml_absent",
+			glob:      "/.github/workflows/*.yml",
+			assertion: StructuredValueAssertion{Path: "/permissions", Absent: true},
+		},
+		{
+			desc:           "yaml_not_absent",
+			glob:           "/.github/workflows/*.yml",
+			assertion:      StructuredValueAssertion{Path: "$.jobs.build['runs-on']", Absent: true},
+			wantError:      true,
+			wantMismatched: []string{"/.github/workflows/ci.yml"},
+		},
+		{
+			desc:      "yaml_regex",
+			glob:      "/.github/workflows/*.yml",
+			assertion: StructuredValueAssertion{Path: "$.jobs.build.steps[0].uses", Regex: strPtr(`^actions/checkout@v[4-9]$`)},
+		},
+		{
+			desc:      "yaml_filter",
+			glob:      "/.github/workflows/*.yml",
+			assertion: StructuredValueAssertion{Path: `/jobs/build/steps/~{"name":"test"}/run`, OneOf: []string{"make test", "go test ./..."}},
+		},
+		{
+			desc:      "toml_one_of",
+			glob:      "/pyproject.toml",
+			assertion: StructuredValueAssertion{Path: "/project/requires-python", OneOf: []string{">=3.8", ">=3.9"}},
+		},
+		{
+			desc:      "no_file",
+			glob:      "/Chart.yaml",
+			assertion: StructuredValueAssertion{Path: "/version"},
+			wantError: true,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			rule := &StructuredValueRule{
+				BaseRule:   new(BaseRule),
+				Glob:       c.glob,
+				Assertions: []StructuredValueAssertion{c.assertion},
+			}
+			err := rule.ExecuteDuringPlan()
+			s.NoError(err)
+			if c.wantError {
+				s.Error(rule.CheckError())
+			} else {
+				s.NoError(rule.CheckError())
+			}
+			s.Equal(c.wantMismatched, rule.MismatchedFiles)
+		})
+	}
+}
+
+func (s *structuredValueRuleSuite) TestStructuredValueRule_ExportValues() {
+	s.dummyFsWithFiles([]string{"/package.json"}, []string{`{"engines": {"node": "18"}, "files": ["dist"]}`})
+	rule := &StructuredValueRule{
+		BaseRule: new(BaseRule),
+		Glob:     "/package.json",
+		Assertions: []StructuredValueAssertion{
+			{Path: "/engines/node"},
+			{Path: "/files"},
+			{Path: "/license", Absent: true},
+		},
+	}
+	err := rule.ExecuteDuringPlan()
+	s.NoError(err)
+	s.NoError(rule.CheckError())
+	s.Equal([]StructuredValue{
+		{File: "/package.json", Path: "/engines/node", Exists: true, Value: "18"},
+		{File: "/package.json", Path: "/files", Exists: true, Value: `["dist"]`},
+		{File: "/package.json", Path: "/license"},
+	}, rule.Values)
+	values := golden.Value(rule)["values"]
+	s.Equal(3, values.LengthInt())
+	s.Equal(cty.StringVal("18"), values.Index(cty.NumberIntVal(0)).GetAttr("value"))
+}
+
+func (s *structuredValueRuleSuite) TestStructuredValueRule_InvalidAssertion() {
+	cases := []StructuredValueAssertion{
+		{Path: "engines.node"},
+		{Path: "$.steps[*]"},
+		{Path: "/engines/node", Equals: strPtr("18"), Regex: strPtr("^18$")},
+		{Path: "/engines/node", Absent: true, OneOf: []string{"18"}},
+		{Path: "/engines/node", Regex: strPtr("(")},
+	}
+	for _, c := range cases {
+		rule := &StructuredValueRule{
+			BaseRule:   new(BaseRule),
+			Glob:       "/package.json",
+			Assertions: []StructuredValueAssertion{c},
+		}
+		s.Error(rule.ExecuteDuringPlan(), c.Path)
+	}
+}
+
+func (s *structuredValueRuleSuite) TestStructuredValueRule_Config() {
+	t := s.T()
+	content := `
+	rule "structured_value" node {
+		glob = "/package.json"
+		assertion {
+			path   = "$.engines.node"
+			equals = 18
+		}
+	}
+
+	fix "local_file" node {
+		rule_ids = [rule.structured_value.node.id]
+		paths    = [for v in rule.structured_value.node.values : "${v.file}.${v.value}"]
+		content  = ""
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/package.json"}, []string{content, `{"engines": {"node": "16"}}`})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	s.Contains(plan.FailedRules[0].CheckError.Error(), `$.engines.node is "16", expected "18"`)
+	fixes := golden.Blocks[Fix](config)
+	require.Len(t, fixes, 1)
+	s.Equal([]string{"/package.json.16"}, fixes[0].(*LocalFileFix).Paths)
+}
+
+func TestJsonPointer(t *testing.T) {
+	cases := map[string]string{
+		"/engines/node":              "/engines/node",
+		"$.engines.node":             "/engines/node",
+		"$.jobs.build.steps[0].uses": "/jobs/build/steps/0/uses",
+		"$['a.b'][\"c/d\"]":          "/a.b/c~1d",
+	}
+	for path, want := range cases {
+		got, err := jsonPointer(path)
+		require.NoError(t, err, path)
+		require.Equal(t, want, got, path)
+	}
+}
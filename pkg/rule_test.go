@@ -0,0 +1,142 @@
+package pkg
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *greptConfigSuite) TestRule_MetaAttributes() {
+	t := s.T()
+	content := `
+	rule "must_be_true" license {
+		condition       = false
+		severity        = "warning"
+		description     = "every repo must have a license"
+		remediation_url = "https://example.com/license"
+		tags            = ["legal", "oss"]
+	}
+
+	rule "must_be_true" readme {
+		condition = false
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+
+	rules := make(map[string]Rule)
+	for _, r := range golden.Blocks[Rule](config) {
+		rules[r.Address()] = r
+	}
+	license := rules["rule.must_be_true.license"]
+	s.Equal(SeverityWarning, license.GetSeverity())
+	s.Equal("every repo must have a license", license.GetDescription())
+	s.Equal("https://example.com/license", license.GetRemediationUrl())
+	s.Equal([]string{"legal", "oss"}, license.GetTags())
+	readme := rules["rule.must_be_true.readme"]
+	s.Equal(SeverityError, readme.GetSeverity())
+	s.Empty(readme.GetTags())
+
+	planString := plan.String()
+	s.Contains(planString, "[warning] rule.must_be_true.license check return failure")
+	s.Contains(planString, "description: every repo must have a license")
+	s.Contains(planString, "remediation: https://example.com/license")
+	s.Less(
+		strings.Index(planString, "rule.must_be_true.readme"),
+		strings.Index(planString, "rule.must_be_true.license"),
+		"rules with error severity should be listed first")
+
+	out, err := plan.Render(OutputFormatSarif)
+	require.NoError(t, err)
+	var report sarifReport
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	levels := make(map[string]string)
+	for _, r := range report.Runs[0].Results {
+		levels[r.RuleId] = r.Level
+	}
+	s.Equal("warning", levels["rule.must_be_true.license"])
+	s.Equal("error", levels["rule.must_be_true.readme"])
+}
+
+func (s *greptConfigSuite) TestRule_InvalidSeverity() {
+	content := `
+	rule "must_be_true" license {
+		condition = false
+		severity  = "fatal"
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(s.T(), err)
+	_, err = RunGreptPlan(config)
+	require.Error(s.T(), err)
+	s.Contains(err.Error(), "invalid severity fatal")
+}
+
+func (s *greptConfigSuite) TestRule_BlockingRulesBySeverityAndTag() {
+	t := s.T()
+	content := `
+	rule "must_be_true" error {
+		condition = false
+	}
+
+	rule "must_be_true" warning {
+		condition = false
+		severity  = "warning"
+		tags      = ["security"]
+	}
+
+	rule "must_be_true" info {
+		condition = false
+		severity  = "info"
+	}
+	`
+	s.dummyFsWithFiles([]string{"test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("", "", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 3)
+
+	cases := []struct {
+		desc      string
+		selectors []string
+		want      []string
+	}{
+		{
+			desc: "default",
+			want: []string{"rule.must_be_true.error"},
+		},
+		{
+			desc:      "severity",
+			selectors: []string{"severity:info"},
+			want:      []string{"rule.must_be_true.info"},
+		},
+		{
+			desc:      "tag",
+			selectors: []string{"tag:security"},
+			want:      []string{"rule.must_be_true.warning"},
+		},
+		{
+			desc:      "mixed",
+			selectors: []string{"tag:security", "rule.must_be_true.error"},
+			want:      []string{"rule.must_be_true.error", "rule.must_be_true.warning"},
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			blocking, err := plan.BlockingRules(c.selectors)
+			s.NoError(err)
+			var addresses []string
+			for _, r := range blocking {
+				addresses = append(addresses, r.Address())
+			}
+			s.ElementsMatch(c.want, addresses)
+		})
+	}
+}
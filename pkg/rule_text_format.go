@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/afero"
+)
+
+var _ Rule = &TextFormatRule{}
+
+type TextFormatRule struct {
+	*golden.BaseBlock
+	*BaseRule
+	Glob                   string   `hcl:"glob"`
+	Exclude                []string `hcl:"exclude,optional"`
+	RespectGitIgnore       bool     `hcl:"respect_gitignore,optional"`
+	Editorconfig           bool     `hcl:"editorconfig,optional"`
+	EndOfLine              string   `hcl:"end_of_line,optional" validate:"omitempty,oneof=lf crlf cr"`
+	TrimTrailingWhitespace *bool    `hcl:"trim_trailing_whitespace,optional"`
+	InsertFinalNewline     *bool    `hcl:"insert_final_newline,optional"`
+	Charset                string   `hcl:"charset,optional" validate:"omitempty,oneof=utf-8 utf-8-bom"`
+	IndentStyle            string   `hcl:"indent_style,optional" validate:"omitempty,oneof=space tab"`
+	IndentSize             int      `hcl:"indent_size,optional" validate:"omitempty,min=1"`
+	MismatchedFiles        []string `attribute:"mismatched_files"`
+}
+
+func (t *TextFormatRule) Type() string {
+	return "text_format"
+}
+
+func (t *TextFormatRule) ExecuteDuringPlan() error {
+	t.MismatchedFiles = nil
+	format := textFormat{
+		EndOfLine:              t.EndOfLine,
+		TrimTrailingWhitespace: t.TrimTrailingWhitespace,
+		InsertFinalNewline:     t.InsertFinalNewline,
+		Charset:                t.Charset,
+		IndentStyle:            t.IndentStyle,
+		IndentSize:             t.IndentSize,
+	}
+	if !t.Editorconfig && format.empty() {
+		return noTextFormatError(t.Address())
+	}
+	fs := FsFactory()
+	files, err := t.glob(fs, newFileWalker(t.Glob, t.Exclude, t.RespectGitIgnore))
+	if err != nil {
+		return fmt.Errorf("error on glob files %s, %s", t.Glob, t.Address())
+	}
+	loader := newEditorconfigLoader(fs)
+	var checkErr error
+	for _, file := range files {
+		fileFormat, ok, err := resolveTextFormat(fs, loader, file, format, t.Editorconfig)
+		if err != nil {
+			return fmt.Errorf("%s: %+v", t.Address(), err)
+		}
+		if !ok {
+			continue
+		}
+		content, err := afero.ReadFile(fs, file)
+		if err != nil {
+			return err
+		}
+		violations := fileFormat.check(content)
+		if len(violations) == 0 {
+			continue
+		}
+		t.MismatchedFiles = append(t.MismatchedFiles, file)
+		checkErr = multierror.Append(checkErr, fmt.Errorf("%s: %s", file, strings.Join(violations, ", ")))
+	}
+	for _, configPath := range loader.configPaths() {
+		t.recordInput(newFileWalker(configPath, nil, false))
+	}
+	if checkErr != nil {
+		t.setCheckError(checkErr)
+	}
+	return nil
+}
+
+func (t *TextFormatRule) violatingFiles() []string {
+	return t.MismatchedFiles
+}
+
+// resolveTextFormat returns the text format of the file, settings from `.editorconfig` are overridden by explicit ones.
+// Directories and binary files are skipped.
+func resolveTextFormat(fs afero.Fs, loader *editorconfigLoader, file string, format textFormat, useEditorconfig bool) (textFormat, bool, error) {
+	isDir, err := afero.IsDir(fs, file)
+	if err != nil || isDir {
+		return format, false, err
+	}
+	binary, err := isBinaryFile(fs, file)
+	if err != nil || binary {
+		return format, false, err
+	}
+	if !useEditorconfig {
+		return format, true, nil
+	}
+	fromEditorconfig, err := loader.textFormat(file)
+	if err != nil {
+		return format, false, err
+	}
+	return fromEditorconfig.override(format), true, nil
+}
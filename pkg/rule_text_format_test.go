@@ -0,0 +1,165 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type textFormatRuleSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestTextFormatRuleSuite(t *testing.T) {
+	suite.Run(t, new(textFormatRuleSuite))
+}
+
+func (s *textFormatRuleSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *textFormatRuleSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *textFormatRuleSuite) TestTextFormatRule_Check() {
+	s.dummyFsWithFiles([]string{"/good.txt", "/crlf.txt", "/trailing.txt", "/no_newline.txt", "/bom.txt", "/latin1.txt", "/tabs.txt", "/spaces.txt", "/image.png"}, []string{
+		"hello\n\tworld\n",
+		"hello\r\nworld\r\n",
+		"hello \nworld\n",
+		"hello\nworld",
+		"\xEF\xBB\xBFhello\n",
+		"caf\xE9\n",
+		"func main() {\n\treturn\n}\n",
+		"func main() {\n    return\n}\n",
+		"\x89PNG\r\n\x1a\n",
+	})
+	yes := true
+	no := false
+	cases := []struct {
+		desc           string
+		rule           *TextFormatRule
+		wantMismatched []string
+	}{
+		{
+			desc:           "end_of_line",
+			rule:           &TextFormatRule{Glob: "/*", EndOfLine: "lf"},
+			wantMismatched: []string{"/crlf.txt"},
+		},
+		{
+			desc:           "trim_trailing_whitespace",
+			rule:           &TextFormatRule{Glob: "/*", TrimTrailingWhitespace: &yes},
+			wantMismatched: []string{"/trailing.txt"},
+		},
+		{
+			desc:           "insert_final_newline",
+			rule:           &TextFormatRule{Glob: "/*", InsertFinalNewline: &yes},
+			wantMismatched: []string{"/no_newline.txt"},
+		},
+		{
+			desc:           "no_final_newline",
+			rule:           &TextFormatRule{Glob: "/no_newline.txt", InsertFinalNewline: &no},
+			wantMismatched: nil,
+		},
+		{
+			desc:           "charset",
+			rule:           &TextFormatRule{Glob: "/*", Charset: "utf-8"},
+			wantMismatched: []string{"/bom.txt", "/latin1.txt"},
+		},
+		{
+			desc:           "indent_style_space",
+			rule:           &TextFormatRule{Glob: "/*", IndentStyle: "space"},
+			wantMismatched: []string{"/good.txt", "/tabs.txt"},
+		},
+		{
+			desc:           "indent_style_tab",
+			rule:           &TextFormatRule{Glob: "/*", IndentStyle: "tab"},
+			wantMismatched: []string{"/spaces.txt"},
+		},
+		{
+			desc:           "indent_size",
+			rule:           &TextFormatRule{Glob: "/*", IndentStyle: "tab", IndentSize: 8},
+			wantMismatched: nil,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			c.rule.BaseRule = new(BaseRule)
+			err := c.rule.ExecuteDuringPlan()
+			s.NoError(err)
+			if c.wantMismatched != nil {
+				s.Error(c.rule.CheckError())
+			} else {
+				s.NoError(c.rule.CheckError())
+			}
+			s.Equal(c.wantMismatched, c.rule.MismatchedFiles)
+		})
+	}
+}
+
+func (s *textFormatRuleSuite) TestTextFormatRule_Editorconfig() {
+	t := s.T()
+	content := `
+	rule "text_format" editorconfig {
+		glob         = "/src/**"
+		editorconfig = true
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/.editorconfig", "/src/.editorconfig", "/src/main.go", "/src/Makefile", "/src/script.sh", "/src/README.md"}, []string{
+		content,
+		"root = true\n\n[*]\nend_of_line = lf\ninsert_final_newline = true\ntrim_trailing_whitespace = true\nindent_style = space\n\n[*.go]\nindent_style = tab\n",
+		"[Makefile]\nindent_style = tab\n\n[*.md]\ntrim_trailing_whitespace = unset\n",
+		"package main\n\nfunc main() {\n\tprintln()\n}\n",
+		"build:\n    go build\n",
+		"#!/bin/sh\r\necho hi \r\n",
+		"# Title  \nline break\n",
+	})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	require.Len(t, plan.FailedRules, 1)
+	checkErr := plan.FailedRules[0].CheckError.Error()
+	s.Contains(checkErr, "/src/Makefile: indentation is not tab on 1 line(s), first at line 2")
+	s.Contains(checkErr, "/src/script.sh: line endings are not LF on 2 line(s), first at line 1, trailing whitespace on 1 line(s), first at line 2")
+	s.NotContains(checkErr, "main.go")
+	s.NotContains(checkErr, "README.md")
+}
+
+func (s *textFormatRuleSuite) TestTextFormatRule_ExplicitSettingsOverrideEditorconfig() {
+	t := s.T()
+	content := `
+	rule "text_format" editorconfig {
+		glob         = "/*.bat"
+		editorconfig = true
+		end_of_line  = "crlf"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl", "/.editorconfig", "/run.bat"}, []string{
+		content,
+		"root = true\n\n[*]\nend_of_line = lf\n",
+		"echo hi\r\n",
+	})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	s.Empty(plan.FailedRules)
+}
+
+func (s *textFormatRuleSuite) TestTextFormatRule_AtLeastOneSettingRequired() {
+	t := s.T()
+	content := `
+	rule "text_format" sample {
+		glob = "/**"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("/", "/", context.TODO(), nil)
+	require.NoError(t, err)
+	_, err = RunGreptPlan(config)
+	require.Error(t, err)
+}
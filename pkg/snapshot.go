@@ -0,0 +1,284 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/afero"
+)
+
+// fsSnapshot records the content of some paths, so we can restore them after a failed apply.
+// A path that doesn't exist is recorded too, restore would remove it.
+type fsSnapshot struct {
+	fs afero.Fs
+	// backup keeps content of recorded files, it's a temp folder for the os file system, so a snapshot of the whole working tree doesn't stay in memory.
+	backup    afero.Fs
+	backupDir string
+	roots     []snapshotRoot
+	backups   int
+}
+
+type snapshotRoot struct {
+	path    string
+	exist   bool
+	entries map[string]*snapshotEntry
+}
+
+type snapshotEntry struct {
+	isDir bool
+	mode  os.FileMode
+	// link is the target of a symlink, symlinks are restored as symlinks instead of copies of their targets.
+	link string
+	// backupName is the name of the file's content in backup.
+	backupName string
+}
+
+func (e *snapshotEntry) isLink() bool {
+	return e.link != ""
+}
+
+func newFsSnapshot(fs afero.Fs, paths []string) (*fsSnapshot, error) {
+	s := &fsSnapshot{
+		fs:     fs,
+		backup: afero.NewMemMapFs(),
+	}
+	if _, ok := fs.(*afero.OsFs); ok {
+		dir, err := os.MkdirTemp("", "grept-snapshot")
+		if err != nil {
+			return nil, err
+		}
+		s.backup, s.backupDir = afero.NewBasePathFs(afero.NewOsFs(), dir), dir
+	}
+	for _, p := range paths {
+		root, err := s.capture(p)
+		if err != nil {
+			_ = s.close()
+			return nil, err
+		}
+		s.roots = append(s.roots, root)
+	}
+	return s, nil
+}
+
+func (s *fsSnapshot) capture(p string) (snapshotRoot, error) {
+	p = filepath.Clean(p)
+	exist, err := afero.Exists(s.fs, p)
+	if err != nil {
+		return snapshotRoot{}, err
+	}
+	if !exist {
+		// the fix might create parent folders too, record the topmost one that doesn't exist
+		for parent := filepath.Dir(p); parent != p; parent = filepath.Dir(parent) {
+			parentExist, err := afero.Exists(s.fs, parent)
+			if err != nil {
+				return snapshotRoot{}, err
+			}
+			if parentExist {
+				break
+			}
+			p = parent
+		}
+		return snapshotRoot{path: p}, nil
+	}
+	root := snapshotRoot{
+		path:    p,
+		exist:   true,
+		entries: make(map[string]*snapshotEntry),
+	}
+	err = afero.Walk(s.fs, p, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if skipInSnapshot(name, info) {
+			return filepath.SkipDir
+		}
+		entry := &snapshotEntry{
+			isDir: info.IsDir(),
+			mode:  info.Mode().Perm(),
+		}
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if entry.link, err = readLink(s.fs, name); err != nil {
+				return err
+			}
+		case !entry.isDir:
+			if entry.backupName, err = s.backupFile(name); err != nil {
+				return err
+			}
+		}
+		root.entries[name] = entry
+		return nil
+	})
+	return root, err
+}
+
+// backupFile copies the file's content into backup, the content is streamed, so large files are not loaded into memory.
+func (s *fsSnapshot) backupFile(name string) (string, error) {
+	s.backups++
+	backupName := fmt.Sprintf("%d", s.backups)
+	src, err := s.fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	dst, err := s.backup.Create(backupName)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = dst.Close()
+	}()
+	_, err = io.Copy(dst, src)
+	return backupName, err
+}
+
+// read returns the recorded content of a regular file.
+func (s *fsSnapshot) read(entry *snapshotEntry) ([]byte, error) {
+	return afero.ReadFile(s.backup, entry.backupName)
+}
+
+// close removes recorded content, the snapshot can't be restored after close.
+func (s *fsSnapshot) close() error {
+	if s.backupDir == "" {
+		return nil
+	}
+	return os.RemoveAll(s.backupDir)
+}
+
+// restore reverts all recorded paths, in the reverse order of capture.
+func (s *fsSnapshot) restore() error {
+	return s.restoreTo(s.fs)
+}
+
+// restoreTo writes all recorded paths into fs, it could be used to copy the recorded paths into another fs.
+func (s *fsSnapshot) restoreTo(fs afero.Fs) error {
+	for i := len(s.roots) - 1; i >= 0; i-- {
+		if err := s.restoreRoot(fs, s.roots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// regularFiles returns content of all recorded regular files.
+func (s *fsSnapshot) regularFiles() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	for _, root := range s.roots {
+		for name, entry := range root.entries {
+			if entry.isDir || entry.isLink() {
+				continue
+			}
+			content, err := s.read(entry)
+			if err != nil {
+				return nil, err
+			}
+			files[name] = content
+		}
+	}
+	return files, nil
+}
+
+func (s *fsSnapshot) restoreRoot(fs afero.Fs, root snapshotRoot) error {
+	if !root.exist {
+		return fs.RemoveAll(root.path)
+	}
+	// remove paths that were created after the snapshot, or whose type has changed
+	var created []string
+	if isDir, _ := afero.IsDir(fs, root.path); isDir {
+		err := afero.Walk(fs, root.path, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if skipInSnapshot(name, info) {
+				return filepath.SkipDir
+			}
+			isLink := info.Mode()&os.ModeSymlink != 0
+			if entry, ok := root.entries[name]; !ok || entry.isDir != info.IsDir() || entry.isLink() != isLink {
+				created = append(created, name)
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	} else if entry := root.entries[root.path]; entry.isDir || entry.isLink() {
+		created = append(created, root.path)
+	}
+	for _, name := range created {
+		if err := fs.RemoveAll(name); err != nil {
+			return err
+		}
+	}
+	names := make([]string, 0, len(root.entries))
+	for name := range root.entries {
+		names = append(names, name)
+	}
+	// parent folders first
+	sort.Strings(names)
+	for _, name := range names {
+		entry := root.entries[name]
+		if entry.isDir {
+			if err := fs.MkdirAll(name, entry.mode); err != nil {
+				return err
+			}
+			if err := fs.Chmod(name, entry.mode); err != nil {
+				return err
+			}
+			continue
+		}
+		if entry.isLink() {
+			if err := restoreLink(fs, name, entry.link); err != nil {
+				return err
+			}
+			continue
+		}
+		content, err := s.read(entry)
+		if err != nil {
+			return err
+		}
+		if err := afero.WriteFile(fs, name, content, entry.mode); err != nil {
+			return err
+		}
+		if err := fs.Chmod(name, entry.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLink(fs afero.Fs, name string) (string, error) {
+	lr, ok := fs.(afero.LinkReader)
+	if !ok {
+		return "", fmt.Errorf("can't read symlink %s", name)
+	}
+	return lr.ReadlinkIfPossible(name)
+}
+
+// restoreLink recreates the symlink unless it still points to the same target.
+func restoreLink(fs afero.Fs, name, target string) error {
+	if current, err := readLink(fs, name); err == nil && current == target {
+		return nil
+	}
+	if err := fs.RemoveAll(name); err != nil {
+		return err
+	}
+	linker, ok := fs.(afero.Linker)
+	if !ok {
+		// in-memory copies used to preview fixes don't support symlinks, they're left out of the preview
+		return nil
+	}
+	return linker.SymlinkIfPossible(target, name)
+}
+
+// skipInSnapshot skips `.git` folder, fixes should not change the git database.
+func skipInSnapshot(name string, info os.FileInfo) bool {
+	return info.IsDir() && filepath.Base(name) == ".git"
+}
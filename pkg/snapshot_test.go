@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type fsSnapshotSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestFsSnapshotSuite(t *testing.T) {
+	suite.Run(t, new(fsSnapshotSuite))
+}
+
+func (s *fsSnapshotSuite) SetupTest() {
+	s.testBase = newTestBase()
+}
+
+func (s *fsSnapshotSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *fsSnapshotSuite) TestRestore() {
+	s.dummyFsWithFiles([]string{"/repo/README.md", "/repo/src/main.go", "/repo/.git/HEAD"}, []string{"readme", "package main", "ref"})
+	snapshot, err := newFsSnapshot(s.fs, []string{"/repo", "/new/folder/file.txt"})
+	s.NoError(err)
+
+	s.NoError(afero.WriteFile(s.fs, "/repo/README.md", []byte("changed"), 0644))
+	s.NoError(s.fs.Remove("/repo/src/main.go"))
+	s.NoError(afero.WriteFile(s.fs, "/repo/src/extra/extra.go", []byte("extra"), 0644))
+	s.NoError(afero.WriteFile(s.fs, "/new/folder/file.txt", []byte("new"), 0644))
+	s.NoError(afero.WriteFile(s.fs, "/repo/.git/HEAD", []byte("new ref"), 0644))
+
+	s.NoError(snapshot.restore())
+	s.fileContent("/repo/README.md", "readme")
+	s.fileContent("/repo/src/main.go", "package main")
+	s.fileContent("/repo/.git/HEAD", "new ref")
+	s.notExist("/repo/src/extra")
+	s.notExist("/new")
+}
+
+func (s *fsSnapshotSuite) TestRestore_FileReplacedByDir() {
+	s.dummyFsWithFiles([]string{"/a"}, []string{"a"})
+	snapshot, err := newFsSnapshot(s.fs, []string{"/a"})
+	s.NoError(err)
+
+	s.NoError(s.fs.Remove("/a"))
+	s.NoError(afero.WriteFile(s.fs, "/a/b", []byte("b"), 0644))
+
+	s.NoError(snapshot.restore())
+	s.fileContent("/a", "a")
+}
+
+func (s *fsSnapshotSuite) TestRestore_SymlinksShouldBeRestoredAsSymlinks() {
+	dir := s.T().TempDir()
+	fs := afero.NewOsFs()
+	s.NoError(afero.WriteFile(fs, filepath.Join(dir, "target.txt"), []byte("target"), 0644))
+	s.NoError(os.Symlink("target.txt", filepath.Join(dir, "link.txt")))
+	snapshot, err := newFsSnapshot(fs, []string{dir})
+	s.NoError(err)
+	defer func() {
+		s.NoError(snapshot.close())
+	}()
+
+	s.NoError(os.Remove(filepath.Join(dir, "link.txt")))
+	s.NoError(afero.WriteFile(fs, filepath.Join(dir, "link.txt"), []byte("replaced"), 0644))
+	s.NoError(afero.WriteFile(fs, filepath.Join(dir, "target.txt"), []byte("changed"), 0644))
+
+	s.NoError(snapshot.restore())
+	link, err := os.Readlink(filepath.Join(dir, "link.txt"))
+	s.NoError(err)
+	s.Equal("target.txt", link)
+	content, err := os.ReadFile(filepath.Join(dir, "target.txt"))
+	s.NoError(err)
+	s.Equal("target", string(content))
+}
+
+func (s *fsSnapshotSuite) fileContent(name, want string) {
+	content, err := afero.ReadFile(s.fs, name)
+	s.NoError(err)
+	s.Equal(want, string(content))
+}
+
+func (s *fsSnapshotSuite) notExist(name string) {
+	exist, err := afero.Exists(s.fs, name)
+	s.NoError(err)
+	s.False(exist)
+}
+
+func (s *fsSnapshotSuite) TestTransaction_WholeTreeShouldBeSnapshottedOnce() {
+	s.T().Chdir(s.T().TempDir())
+	fs := afero.NewOsFs()
+	s.NoError(afero.WriteFile(fs, "README.md", []byte("readme"), 0644))
+	s.NoError(afero.WriteFile(fs, "main.go", []byte("package main"), 0644))
+	t := &transaction{fs: fs}
+	defer t.close()
+
+	s.NoError(t.snapshot([]string{"."}))
+	s.NoError(afero.WriteFile(fs, "README.md", []byte("changed"), 0644))
+	s.NoError(t.snapshot([]string{"./"}))
+	s.NoError(afero.WriteFile(fs, "main.go", []byte("changed"), 0644))
+	s.NoError(t.snapshot([]string{"new.go"}))
+	s.NoError(afero.WriteFile(fs, "new.go", []byte("new"), 0644))
+	s.Len(t.snapshots, 2)
+
+	s.NoError(t.rollback())
+	for name, want := range map[string]string{"README.md": "readme", "main.go": "package main"} {
+		content, err := afero.ReadFile(fs, name)
+		s.NoError(err)
+		s.Equal(want, string(content))
+	}
+	exists, err := afero.Exists(fs, "new.go")
+	s.NoError(err)
+	s.False(exists)
+}
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	yaml "github.com/lonegunmanb/atomatt-yaml"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/afero"
+)
+
+// structuredFormat returns format if it's set, or infers the format from the file extension.
+func structuredFormat(file, format string) (string, error) {
+	if format != "" {
+		return format, nil
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return "json", nil
+	case ".yaml", ".yml":
+		return "yaml", nil
+	case ".toml":
+		return "toml", nil
+	}
+	return "", fmt.Errorf("cannot infer format of %s from its extension, please set `format`", file)
+}
+
+// parseStructuredFile reads json, yaml or toml file as a yaml node, json is parsed as yaml since yaml is a superset of json.
+func parseStructuredFile(fs afero.Fs, file, format string) (*yaml.Node, error) {
+	format, err := structuredFormat(file, format)
+	if err != nil {
+		return nil, err
+	}
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+	root := new(yaml.Node)
+	if format != "toml" {
+		if err = yaml.Unmarshal(content, root); err != nil {
+			return nil, fmt.Errorf("error on parsing %s file %s: %+v", format, file, err)
+		}
+		return root, nil
+	}
+	var v map[string]any
+	if err = toml.Unmarshal(content, &v); err != nil {
+		return nil, fmt.Errorf("error on parsing toml file %s: %+v", file, err)
+	}
+	// re-encode toml as yaml so all formats could be queried by yaml-jsonpointer
+	y, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing toml file %s: %+v", file, err)
+	}
+	if err = yaml.Unmarshal(y, root); err != nil {
+		return nil, fmt.Errorf("error on parsing toml file %s: %+v", file, err)
+	}
+	return root, nil
+}
@@ -0,0 +1,169 @@
+package pkg
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
+)
+
+// greptIgnoreFile is a file in the working directory that contains `suppression` blocks only, so a repository could have its own exceptions to a shared config.
+const greptIgnoreFile = ".greptignore"
+
+const suppressionDateLayout = "2006-01-02"
+
+var timeNow = time.Now
+
+// Suppression is an accepted exception to a rule, failures of suppressed rules are reported separately and don't trigger fixes.
+type Suppression struct {
+	// Rule is the address of the suppressed rule, like `rule.file_exist.security_md`, `rule.file_hash.license["a"]` for a `for_each` instance, or with wildcards like `rule.file_hash.*`.
+	Rule   string `hcl:"rule"`
+	Reason string `hcl:"reason"`
+	// ExpiresOn is a date like `2027-01-01`, the suppression stops working on that day, the rule's failure would be reported again.
+	ExpiresOn string `hcl:"expires_on,optional"`
+	// Fingerprints are fingerprints of findings reported by rules like `no_secrets`, the suppression only suppresses these findings instead of the whole rule.
+	Fingerprints []string `hcl:"fingerprints,optional"`
+	Range        hcl.Range
+	expiresOn    time.Time
+}
+
+func (s *Suppression) expired() bool {
+	return s.ExpiresOn != "" && !timeNow().Before(s.expiresOn)
+}
+
+func (s *Suppression) match(address string) bool {
+	match, _ := selectorsMatch([]string{s.Rule}, address, nil, "")
+	return match
+}
+
+func (s *Suppression) String() string {
+	if s.ExpiresOn == "" {
+		return fmt.Sprintf("suppressed by %s: %s", s.Range.String(), s.Reason)
+	}
+	return fmt.Sprintf("suppressed by %s until %s: %s", s.Range.String(), s.ExpiresOn, s.Reason)
+}
+
+func newSuppression(b *hclsyntax.Block) (*Suppression, error) {
+	s := &Suppression{
+		Range: b.Range(),
+	}
+	if len(b.Labels) > 0 {
+		return nil, fmt.Errorf("`suppression` block doesn't have labels: %s", s.Range.String())
+	}
+	// suppressions are not evaluated with the config, so they must be literal values
+	if diag := gohcl.DecodeBody(b.Body, nil, s); diag.HasErrors() {
+		return nil, diag
+	}
+	if !strings.HasPrefix(s.Rule, "rule.") {
+		return nil, fmt.Errorf("`rule` of suppression must be a rule address like `rule.file_exist.security_md`, got %s: %s", s.Rule, s.Range.String())
+	}
+	if strings.TrimSpace(s.Reason) == "" {
+		return nil, fmt.Errorf("`reason` of suppression must not be empty: %s", s.Range.String())
+	}
+	if s.ExpiresOn != "" {
+		expiresOn, err := time.ParseInLocation(suppressionDateLayout, s.ExpiresOn, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("`expires_on` of suppression must be a date like 2027-01-01, got %s: %s", s.ExpiresOn, s.Range.String())
+		}
+		s.expiresOn = expiresOn
+	}
+	return s, nil
+}
+
+// loadGreptIgnore reads suppressions from `.greptignore` under dir, it returns nothing when the file doesn't exist.
+func loadGreptIgnore(dir string) ([]*Suppression, error) {
+	fs := FsFactory()
+	filename := filepath.Join(dir, greptIgnoreFile)
+	exist, err := afero.Exists(fs, filename)
+	if err != nil || !exist {
+		return nil, err
+	}
+	content, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+	file, diag := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	if diag.HasErrors() {
+		return nil, diag
+	}
+	var suppressions []*Suppression
+	for _, b := range file.Body.(*hclsyntax.Body).Blocks {
+		if b.Type != "suppression" {
+			err = multierror.Append(err, fmt.Errorf("only `suppression` block is allowed in %s, got %s: %s", greptIgnoreFile, b.Type, b.Range().String()))
+			continue
+		}
+		s, sErr := newSuppression(b)
+		if sErr != nil {
+			err = multierror.Append(err, sErr)
+			continue
+		}
+		suppressions = append(suppressions, s)
+	}
+	return suppressions, err
+}
+
+// suppressionOf returns the first suppression that matches the rule, expired suppressions are returned too so their failures could mention them.
+// Suppressions with fingerprints are not returned, they're applied by suppressFindings.
+func suppressionOf(suppressions []*Suppression, r Rule) *Suppression {
+	var expired *Suppression
+	for _, s := range suppressions {
+		if len(s.Fingerprints) > 0 || !s.match(r.Address()) {
+			continue
+		}
+		if !s.expired() {
+			return s
+		}
+		if expired == nil {
+			expired = s
+		}
+	}
+	return expired
+}
+
+// findingsRule is implemented by rules that report individual findings with fingerprints.
+type findingsRule interface {
+	// suppressFindings updates the check error without the findings that have these fingerprints.
+	suppressFindings(fingerprints map[string]bool)
+}
+
+// suppressFindings removes findings suppressed by fingerprints from the rule's check error, it returns the first matched suppression.
+// Expired suppressions are ignored, so their findings are reported again.
+func suppressFindings(suppressions []*Suppression, r Rule) *Suppression {
+	fr, ok := r.(findingsRule)
+	if !ok {
+		return nil
+	}
+	var matched *Suppression
+	fingerprints := make(map[string]bool)
+	for _, s := range suppressions {
+		if len(s.Fingerprints) == 0 || s.expired() || !s.match(r.Address()) {
+			continue
+		}
+		if matched == nil {
+			matched = s
+		}
+		for _, fp := range s.Fingerprints {
+			fingerprints[fp] = true
+		}
+	}
+	if matched != nil {
+		fr.suppressFindings(fingerprints)
+	}
+	return matched
+}
+
+// SuppressedRule is a failed rule that is suppressed, it's not treated as a failure.
+type SuppressedRule struct {
+	*FailedRule
+	Suppression *Suppression
+}
+
+func (sr *SuppressedRule) String() string {
+	return fmt.Sprintf("%s, %s", sr.FailedRule.String(), sr.Suppression.String())
+}
@@ -0,0 +1,175 @@
+package pkg
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prashantv/gostub"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *greptConfigSuite) TestSuppression_SuppressedRuleShouldNotTriggerFix() {
+	t := s.T()
+	content := `
+	locals {
+		items = toset(["a", "b"])
+	}
+
+	rule "must_be_true" security_md {
+		condition = false
+	}
+
+	rule "must_be_true" readme {
+		for_each = local.items
+		condition = false
+	}
+
+	rule "must_be_true" license {
+		condition = false
+	}
+
+	fix "local_file" security_md {
+		rule_ids = [rule.must_be_true.security_md.id]
+		paths = ["/SECURITY.md"]
+		content = "security"
+	}
+
+	suppression {
+		rule = "rule.must_be_true.security_md"
+		reason = "security policy is on the way"
+		expires_on = "2027-01-01"
+	}
+
+	suppression {
+		rule = "rule.must_be_true.readme[\"a\"]"
+		reason = "readme a is generated"
+	}
+
+	suppression {
+		rule = "rule.must_be_true.license"
+		reason = "expired"
+		expires_on = "2026-01-01"
+	}
+	`
+	stub := gostub.Stub(&timeNow, func() time.Time {
+		return time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local)
+	})
+	defer stub.Reset()
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{content})
+	config, err := BuildGreptConfig("/", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+
+	var suppressed []string
+	for _, sr := range plan.SuppressedRules {
+		suppressed = append(suppressed, sr.Address())
+	}
+	s.ElementsMatch([]string{"rule.must_be_true.security_md", "rule.must_be_true.readme[a]"}, suppressed)
+	var failed []string
+	for _, fr := range plan.FailedRules {
+		failed = append(failed, fr.Address())
+	}
+	s.ElementsMatch([]string{"rule.must_be_true.readme[b]", "rule.must_be_true.license"}, failed)
+	for _, fr := range plan.FailedRules {
+		if fr.Address() == "rule.must_be_true.license" {
+			s.Contains(fr.CheckError.Error(), "expired on 2026-01-01")
+		}
+	}
+	s.Empty(plan.Fixes)
+	s.Contains(plan.String(), "[suppressed] rule.must_be_true.security_md")
+
+	rendered, err := plan.Render(OutputFormatJson)
+	require.NoError(t, err)
+	var report jsonPlanReport
+	require.NoError(t, json.Unmarshal([]byte(rendered), &report))
+	require.Len(t, report.SuppressedRules, 2)
+	s.Equal("readme a is generated", report.SuppressedRules[0].Reason)
+	s.Equal("2027-01-01", report.SuppressedRules[1].ExpiresOn)
+}
+
+func (s *greptConfigSuite) TestSuppression_GreptIgnoreFile() {
+	t := s.T()
+	content := `
+	rule "must_be_true" security_md {
+		condition = false
+	}
+	`
+	ignore := `
+	suppression {
+		rule = "rule.must_be_true.*"
+		reason = "not ready yet"
+	}
+	`
+	s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl", "/repo/.greptignore"}, []string{content, ignore})
+	config, err := BuildGreptConfig("/repo", "/cfg", nil, nil)
+	require.NoError(t, err)
+	plan, err := RunGreptPlan(config)
+	require.NoError(t, err)
+	s.Empty(plan.FailedRules)
+	require.Len(t, plan.SuppressedRules, 1)
+	s.Equal("not ready yet", plan.SuppressedRules[0].Suppression.Reason)
+}
+
+func (s *greptConfigSuite) TestSuppression_Invalid() {
+	cases := []struct {
+		desc    string
+		content string
+		want    string
+	}{
+		{
+			desc: "missing reason",
+			content: `
+			suppression {
+				rule = "rule.must_be_true.sample"
+			}
+			`,
+			want: "reason",
+		},
+		{
+			desc: "empty reason",
+			content: `
+			suppression {
+				rule = "rule.must_be_true.sample"
+				reason = " "
+			}
+			`,
+			want: "`reason` of suppression must not be empty",
+		},
+		{
+			desc: "not rule address",
+			content: `
+			suppression {
+				rule = "fix.local_file.sample"
+				reason = "test"
+			}
+			`,
+			want: "must be a rule address",
+		},
+		{
+			desc: "invalid date",
+			content: `
+			suppression {
+				rule = "rule.must_be_true.sample"
+				reason = "test"
+				expires_on = "01/01/2027"
+			}
+			`,
+			want: "`expires_on` of suppression must be a date",
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			s.fs = afero.NewMemMapFs()
+			s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{`
+			rule "must_be_true" sample {
+				condition = false
+			}
+			` + c.content})
+			_, err := BuildGreptConfig("/", "/cfg", nil, nil)
+			s.NotNil(err)
+			s.Contains(err.Error(), c.want)
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/editorconfig/editorconfig-core-go/v2"
+	"github.com/spf13/afero"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+var endOfLines = map[string]string{
+	"lf":   "\n",
+	"crlf": "\r\n",
+	"cr":   "\r",
+}
+
+const defaultIndentSize = 4
+
+// textFormat is the expected format of text files, it uses the same property names and values as `.editorconfig`,
+// empty strings and nil pointers are not enforced.
+type textFormat struct {
+	EndOfLine              string
+	TrimTrailingWhitespace *bool
+	InsertFinalNewline     *bool
+	Charset                string
+	IndentStyle            string
+	IndentSize             int
+}
+
+func (t textFormat) empty() bool {
+	return t.EndOfLine == "" && t.TrimTrailingWhitespace == nil && t.InsertFinalNewline == nil && t.Charset == "" && t.IndentStyle == ""
+}
+
+func noTextFormatError(address string) error {
+	return fmt.Errorf("%s: one of `editorconfig`, `end_of_line`, `trim_trailing_whitespace`, `insert_final_newline`, `charset` or `indent_style` must be set", address)
+}
+
+// override returns a copy of t with settings that are set in o.
+func (t textFormat) override(o textFormat) textFormat {
+	if o.EndOfLine != "" {
+		t.EndOfLine = o.EndOfLine
+	}
+	if o.TrimTrailingWhitespace != nil {
+		t.TrimTrailingWhitespace = o.TrimTrailingWhitespace
+	}
+	if o.InsertFinalNewline != nil {
+		t.InsertFinalNewline = o.InsertFinalNewline
+	}
+	if o.Charset != "" {
+		t.Charset = o.Charset
+	}
+	if o.IndentStyle != "" {
+		t.IndentStyle = o.IndentStyle
+	}
+	if o.IndentSize > 0 {
+		t.IndentSize = o.IndentSize
+	}
+	return t
+}
+
+func (t textFormat) indentSize() int {
+	if t.IndentSize > 0 {
+		return t.IndentSize
+	}
+	return defaultIndentSize
+}
+
+// skipped returns true for charsets whose lines cannot be processed byte by byte.
+func (t textFormat) skipped() bool {
+	return strings.HasPrefix(t.Charset, "utf-16")
+}
+
+func (t textFormat) checkCharset() bool {
+	return t.Charset == editorconfig.CharsetUTF8 || t.Charset == editorconfig.CharsetUTF8BOM
+}
+
+// check returns the violations of the content.
+func (t textFormat) check(content []byte) []string {
+	if t.skipped() {
+		return nil
+	}
+	var violations []string
+	hasBOM := bytes.HasPrefix(content, utf8BOM)
+	if t.Charset == editorconfig.CharsetUTF8 && hasBOM {
+		violations = append(violations, "UTF-8 BOM")
+	}
+	if t.Charset == editorconfig.CharsetUTF8BOM && !hasBOM {
+		violations = append(violations, "missing UTF-8 BOM")
+	}
+	if t.checkCharset() && !utf8.Valid(content) {
+		violations = append(violations, "invalid UTF-8")
+	}
+	eol := endOfLines[t.EndOfLine]
+	var wrongEol, trailing, indent []int
+	for i, line := range splitTextLines(bytes.TrimPrefix(content, utf8BOM)) {
+		if eol != "" && line.eol != "" && line.eol != eol {
+			wrongEol = append(wrongEol, i+1)
+		}
+		if t.TrimTrailingWhitespace != nil && *t.TrimTrailingWhitespace && len(bytes.TrimRight(line.content, " \t")) != len(line.content) {
+			trailing = append(trailing, i+1)
+		}
+		if t.badIndent(line.content) {
+			indent = append(indent, i+1)
+		}
+	}
+	violations = appendLineViolation(violations, fmt.Sprintf("line endings are not %s", strings.ToUpper(t.EndOfLine)), wrongEol)
+	violations = appendLineViolation(violations, "trailing whitespace", trailing)
+	violations = appendLineViolation(violations, fmt.Sprintf("indentation is not %s", t.IndentStyle), indent)
+	if t.InsertFinalNewline != nil && len(content) > 0 {
+		final := endsWithNewline(content)
+		if *t.InsertFinalNewline && !final {
+			violations = append(violations, "missing final newline")
+		}
+		if !*t.InsertFinalNewline && final {
+			violations = append(violations, "unexpected final newline")
+		}
+	}
+	return violations
+}
+
+// format returns the content in the expected format, invalid UTF-8 sequences are kept as is.
+func (t textFormat) format(content []byte) []byte {
+	if t.skipped() {
+		return content
+	}
+	hasBOM := bytes.HasPrefix(content, utf8BOM)
+	lines := splitTextLines(bytes.TrimPrefix(content, utf8BOM))
+	eol := endOfLines[t.EndOfLine]
+	if eol == "" {
+		eol = "\n"
+		if len(lines) > 0 && lines[0].eol != "" {
+			eol = lines[0].eol
+		}
+	}
+	var buf bytes.Buffer
+	if t.Charset == editorconfig.CharsetUTF8BOM || (hasBOM && t.Charset != editorconfig.CharsetUTF8) {
+		buf.Write(utf8BOM)
+	}
+	for _, line := range lines {
+		text := line.content
+		if t.TrimTrailingWhitespace != nil && *t.TrimTrailingWhitespace {
+			text = bytes.TrimRight(text, " \t")
+		}
+		buf.Write(t.reindent(text))
+		if line.eol == "" {
+			continue
+		}
+		if t.EndOfLine != "" {
+			buf.WriteString(eol)
+		} else {
+			buf.WriteString(line.eol)
+		}
+	}
+	formatted := buf.Bytes()
+	if t.InsertFinalNewline == nil || len(bytes.TrimPrefix(formatted, utf8BOM)) == 0 {
+		return formatted
+	}
+	if *t.InsertFinalNewline && !endsWithNewline(formatted) {
+		formatted = append(formatted, eol...)
+	}
+	if !*t.InsertFinalNewline {
+		formatted = bytes.TrimRight(formatted, "\r\n")
+	}
+	return formatted
+}
+
+func (t textFormat) badIndent(line []byte) bool {
+	indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+	switch t.IndentStyle {
+	case editorconfig.IndentStyleSpaces:
+		return bytes.IndexByte(indent, '\t') >= 0
+	case editorconfig.IndentStyleTab:
+		return bytes.HasPrefix(indent, bytes.Repeat([]byte(" "), t.indentSize()))
+	}
+	return false
+}
+
+// reindent converts the indentation of the line to the indent style, for tab style leading spaces are converted to tabs,
+// remaining spaces that are fewer than the indent size are kept for alignment.
+func (t textFormat) reindent(line []byte) []byte {
+	rest := line[len(line)-len(bytes.TrimLeft(line, " \t")):]
+	indent := line[:len(line)-len(rest)]
+	size := t.indentSize()
+	switch t.IndentStyle {
+	case editorconfig.IndentStyleSpaces:
+		indent = bytes.ReplaceAll(indent, []byte("\t"), bytes.Repeat([]byte(" "), size))
+	case editorconfig.IndentStyleTab:
+		spaces := len(indent) - len(bytes.TrimLeft(indent, " "))
+		indent = append(bytes.Repeat([]byte("\t"), spaces/size), indent[spaces-spaces%size:]...)
+	default:
+		return line
+	}
+	return append(append([]byte{}, indent...), rest...)
+}
+
+type textLine struct {
+	content []byte
+	eol     string
+}
+
+// splitTextLines splits the content into lines, `\n`, `\r\n` and `\r` are all line endings.
+func splitTextLines(content []byte) []textLine {
+	var lines []textLine
+	for len(content) > 0 {
+		i := bytes.IndexAny(content, "\r\n")
+		if i < 0 {
+			lines = append(lines, textLine{content: content})
+			break
+		}
+		eol := content[i : i+1]
+		if content[i] == '\r' && i+1 < len(content) && content[i+1] == '\n' {
+			eol = content[i : i+2]
+		}
+		lines = append(lines, textLine{content: content[:i], eol: string(eol)})
+		content = content[i+len(eol):]
+	}
+	return lines
+}
+
+func endsWithNewline(content []byte) bool {
+	return bytes.HasSuffix(content, []byte("\n")) || bytes.HasSuffix(content, []byte("\r"))
+}
+
+func appendLineViolation(violations []string, violation string, lines []int) []string {
+	if len(lines) == 0 {
+		return violations
+	}
+	return append(violations, fmt.Sprintf("%s on %d line(s), first at line %d", violation, len(lines), lines[0]))
+}
+
+// editorconfigLoader reads settings from `.editorconfig` files, parsed files are cached by directory.
+type editorconfigLoader struct {
+	fs    afero.Fs
+	cache map[string]*editorconfig.Editorconfig
+}
+
+func newEditorconfigLoader(fs afero.Fs) *editorconfigLoader {
+	return &editorconfigLoader{
+		fs:    fs,
+		cache: make(map[string]*editorconfig.Editorconfig),
+	}
+}
+
+// textFormat returns the text format of the file defined by `.editorconfig` files, from the directory of the file up to
+// the one with `root = true`, settings in closer files take precedence.
+func (l *editorconfigLoader) textFormat(file string) (textFormat, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return textFormat{}, err
+	}
+	abs = filepath.ToSlash(abs)
+	raw := make(map[string]string)
+	for dir := path.Dir(abs); ; dir = path.Dir(dir) {
+		ec, err := l.load(dir)
+		if err != nil {
+			return textFormat{}, err
+		}
+		if ec != nil {
+			def, err := ec.GetDefinitionForFilename(strings.TrimPrefix(abs, strings.TrimSuffix(dir, "/")))
+			if err != nil {
+				return textFormat{}, fmt.Errorf("error on matching %s with %s: %+v", file, path.Join(dir, editorconfig.ConfigNameDefault), err)
+			}
+			for k, v := range def.Raw {
+				if _, ok := raw[k]; !ok {
+					raw[k] = v
+				}
+			}
+			if ec.Root {
+				break
+			}
+		}
+		if path.Dir(dir) == dir {
+			break
+		}
+	}
+	return newTextFormatFromEditorconfig(raw), nil
+}
+
+// configPaths returns paths of `.editorconfig` files that have been looked up, including the ones that don't exist.
+func (l *editorconfigLoader) configPaths() []string {
+	paths := make([]string, 0, len(l.cache))
+	for dir := range l.cache {
+		paths = append(paths, path.Join(dir, editorconfig.ConfigNameDefault))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (l *editorconfigLoader) load(dir string) (*editorconfig.Editorconfig, error) {
+	if ec, ok := l.cache[dir]; ok {
+		return ec, nil
+	}
+	f, err := l.fs.Open(filepath.FromSlash(path.Join(dir, editorconfig.ConfigNameDefault)))
+	if os.IsNotExist(err) {
+		l.cache[dir] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	// invalid values are only warnings, they're ignored like editors do
+	ec, _, err := editorconfig.ParseGraceful(f)
+	if err != nil {
+		return nil, fmt.Errorf("error on parsing %s: %+v", path.Join(dir, editorconfig.ConfigNameDefault), err)
+	}
+	l.cache[dir] = ec
+	return ec, nil
+}
+
+// newTextFormatFromEditorconfig converts raw `.editorconfig` properties to text format, `unset` and invalid values are ignored.
+func newTextFormatFromEditorconfig(raw map[string]string) textFormat {
+	value := func(key string) string {
+		v := strings.ToLower(raw[key])
+		if v == editorconfig.UnsetValue {
+			return ""
+		}
+		return v
+	}
+	boolValue := func(key string) *bool {
+		b, err := strconv.ParseBool(value(key))
+		if err != nil {
+			return nil
+		}
+		return &b
+	}
+	var t textFormat
+	if _, ok := endOfLines[value("end_of_line")]; ok {
+		t.EndOfLine = value("end_of_line")
+	}
+	t.TrimTrailingWhitespace = boolValue("trim_trailing_whitespace")
+	t.InsertFinalNewline = boolValue("insert_final_newline")
+	t.Charset = value("charset")
+	if s := value("indent_style"); s == editorconfig.IndentStyleSpaces || s == editorconfig.IndentStyleTab {
+		t.IndentStyle = s
+	}
+	indentSize := value("indent_size")
+	if indentSize == editorconfig.IndentStyleTab {
+		indentSize = value("tab_width")
+	}
+	if size, err := strconv.Atoi(indentSize); err == nil && size > 0 {
+		t.IndentSize = size
+	}
+	return t
+}
@@ -0,0 +1,172 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Azure/golden"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/lonegunmanb/go-defaults"
+	"github.com/lonegunmanb/hclfuncs"
+)
+
+// ConfigValidation is the result of ValidateConfig.
+type ConfigValidation struct {
+	// Errors are mistakes that would fail plan or apply.
+	Errors []error
+	// Warnings are suspicious blocks that don't fail plan or apply, like fixes that would never be applied.
+	Warnings []string
+}
+
+// Valid returns true if there's no error.
+func (v *ConfigValidation) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+func (v *ConfigValidation) addError(err error) {
+	v.Errors = append(v.Errors, err)
+}
+
+// ValidateConfig checks config files in cfgDir without running plan, so no data source is executed. It checks block types, required and unsupported attributes,
+// `depends_on`, and addresses in `rule_ids`. Validation tags are checked for blocks whose attributes are all literal values or function calls,
+// attributes that reference other blocks can only be known during plan.
+func ValidateConfig(baseDir, cfgDir string) (*ConfigValidation, error) {
+	v := new(ConfigValidation)
+	blocks, _, err := loadGreptHclBlocks(false, cfgDir)
+	if err != nil {
+		v.addError(err)
+		return v, nil
+	}
+	if _, err = loadGreptIgnore(baseDir); err != nil {
+		v.addError(fmt.Errorf("error loading %s: %+v", greptIgnoreFile, err))
+	}
+	if err = validateDependencies(blocks); err != nil {
+		v.addError(err)
+	}
+	evalContext := &hcl.EvalContext{
+		Functions: hclfuncs.Functions(baseDir),
+	}
+	rules := make(map[string]bool)
+	for _, b := range blocks {
+		if b.Type == "rule" {
+			rules[referenceAddress(b)] = true
+		}
+	}
+	fixedRules := make(map[string]bool)
+	for _, b := range blocks {
+		switch b.Type {
+		case "rule", "data":
+			v.validateBlock(b, evalContext)
+		case "fix":
+			v.validateBlock(b, evalContext)
+			v.validateRuleIds(b, rules, fixedRules)
+		}
+	}
+	var unfixed []string
+	for address := range rules {
+		if !fixedRules[address] {
+			unfixed = append(unfixed, address)
+		}
+	}
+	sort.Strings(unfixed)
+	for _, address := range unfixed {
+		v.Warnings = append(v.Warnings, fmt.Sprintf("%s is not referenced by any fix's `rule_ids`, its failures can only be reported", address))
+	}
+	return v, nil
+}
+
+func (v *ConfigValidation) validateBlock(b *golden.HclBlock, evalContext *hcl.EvalContext) {
+	address := referenceAddress(b)
+	if len(b.Labels) != 2 {
+		v.addError(fmt.Errorf("%s block must have a type label and a name label: %s", b.Type, b.Range().String()))
+		return
+	}
+	prototype, ok := blockPrototypes[b.Type+"."+b.Labels[0]]
+	if !ok {
+		v.addError(fmt.Errorf("unsupported %s type %s: %s", b.Type, b.Labels[0], b.Range().String()))
+		return
+	}
+	body := staticBody(b.Body)
+	schema, _ := gohcl.ImpliedBodySchema(prototype)
+	if _, diag := body.Content(schema); diag.HasErrors() {
+		v.addError(fmt.Errorf("%s: %s", address, diag.Error()))
+		return
+	}
+	if !isStaticBody(b.Body) {
+		return
+	}
+	instance := reflect.New(reflect.TypeOf(prototype).Elem()).Interface()
+	if diag := gohcl.DecodeBody(body, evalContext, instance); diag.HasErrors() {
+		v.addError(fmt.Errorf("%s: %s", address, diag.Error()))
+		return
+	}
+	defaults.SetDefaults(instance)
+	if err := golden.Validate.Struct(instance); err != nil {
+		v.addError(fmt.Errorf("%s is not valid: %s", address, err.Error()))
+	}
+}
+
+func (v *ConfigValidation) validateRuleIds(b *golden.HclBlock, rules, fixedRules map[string]bool) {
+	address := referenceAddress(b)
+	attr, ok := b.Body.Attributes["rule_ids"]
+	if !ok {
+		v.addError(fmt.Errorf("%s: missing required attribute `rule_ids`, every `fix` block must define `rule_ids`: %s", address, b.Range().String()))
+		return
+	}
+	refs := ruleIdsReferences(b)
+	for _, ref := range refs {
+		if !rules[ref] {
+			v.addError(fmt.Errorf("%s references %s in `rule_ids`, which is not declared: %s", address, ref, attr.SrcRange.String()))
+			continue
+		}
+		fixedRules[ref] = true
+	}
+	// rule ids could be computed, like from a local, only an empty list is known to be unused
+	if len(refs) == 0 && len(attr.Expr.Variables()) == 0 {
+		v.Warnings = append(v.Warnings, fmt.Sprintf("%s doesn't reference any rule in `rule_ids`, it would never be applied: %s", address, attr.SrcRange.String()))
+	}
+}
+
+// staticBody returns the body without meta attributes and meta nested blocks, which are handled by the framework instead of the block.
+func staticBody(body *hclsyntax.Body) *hclsyntax.Body {
+	r := &hclsyntax.Body{
+		Attributes: make(hclsyntax.Attributes),
+		SrcRange:   body.SrcRange,
+		EndRange:   body.EndRange,
+	}
+	for name, attr := range body.Attributes {
+		if !golden.MetaAttributeNames.Contains(name) {
+			r.Attributes[name] = attr
+		}
+	}
+	for _, nb := range body.Blocks {
+		if !golden.MetaNestedBlockNames.Contains(nb.Type) {
+			r.Blocks = append(r.Blocks, nb)
+		}
+	}
+	return r
+}
+
+// isStaticBody returns true if the block could be decoded without evaluating other blocks, `for_each` and `dynamic` blocks are not static.
+func isStaticBody(body *hclsyntax.Body) bool {
+	if _, ok := body.Attributes["for_each"]; ok {
+		return false
+	}
+	for _, attr := range staticBody(body).Attributes {
+		if len(attr.Expr.Variables()) > 0 {
+			return false
+		}
+	}
+	for _, nb := range body.Blocks {
+		if nb.Type == "dynamic" {
+			return false
+		}
+		if !golden.MetaNestedBlockNames.Contains(nb.Type) && !isStaticBody(nb.Body) {
+			return false
+		}
+	}
+	return true
+}
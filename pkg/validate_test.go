@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"github.com/spf13/afero"
+)
+
+func (s *greptConfigSuite) TestValidateConfig() {
+	cases := []struct {
+		desc         string
+		content      string
+		wantErrors   []string
+		wantWarnings []string
+	}{
+		{
+			desc: "valid config",
+			content: `
+			data "http" "license" {
+				url = "https://example.invalid/LICENSE"
+			}
+
+			rule "file_hash" "license" {
+				glob = "LICENSE"
+				hash = sha1(data.http.license.response_body)
+			}
+
+			fix "local_file" "license" {
+				rule_ids = [rule.file_hash.license.id]
+				paths    = ["LICENSE"]
+				content  = data.http.license.response_body
+			}
+			`,
+		},
+		{
+			desc: "unsupported block type",
+			content: `
+			rule "not_exist" "sample" {
+			}
+			`,
+			wantErrors: []string{"unsupported rule type not_exist"},
+		},
+		{
+			desc: "missing required attribute",
+			content: `
+			rule "file_content" "sample" {
+				literal = ["MIT"]
+			}
+			`,
+			wantErrors: []string{"rule.file_content.sample", `The argument "glob" is required`},
+		},
+		{
+			desc: "unsupported attribute",
+			content: `
+			rule "must_be_true" "sample" {
+				condition = false
+				unknown   = true
+			}
+			`,
+			wantErrors: []string{"rule.must_be_true.sample", `An argument named "unknown" is not expected here`},
+		},
+		{
+			desc: "invalid static attribute",
+			content: `
+			data "http" "sample" {
+				url    = "https://example.invalid"
+				method = "FOO"
+			}
+			`,
+			wantErrors: []string{"data.http.sample is not valid", "Method"},
+		},
+		{
+			desc: "undeclared rule in rule_ids",
+			content: `
+			rule "must_be_true" "sample" {
+				condition = false
+			}
+
+			fix "local_file" "sample" {
+				rule_ids = [rule.must_be_true.sample.id, rule.must_be_true.missing.id]
+				paths    = ["/a"]
+				content  = "a"
+			}
+			`,
+			wantErrors: []string{"fix.local_file.sample references rule.must_be_true.missing in `rule_ids`, which is not declared"},
+		},
+		{
+			desc: "unused rule and fix",
+			content: `
+			rule "must_be_true" "sample" {
+				condition = false
+			}
+
+			fix "local_file" "sample" {
+				rule_ids = []
+				paths    = ["/a"]
+				content  = "a"
+			}
+			`,
+			wantWarnings: []string{
+				"fix.local_file.sample doesn't reference any rule in `rule_ids`",
+				"rule.must_be_true.sample is not referenced by any fix's `rule_ids`",
+			},
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			s.fs = afero.NewMemMapFs()
+			s.dummyFsWithFiles([]string{"/cfg/test.grept.hcl"}, []string{c.content})
+			v, err := ValidateConfig("/", "/cfg")
+			s.Nil(err)
+			if len(c.wantErrors) == 0 {
+				s.True(v.Valid(), "%+v", v.Errors)
+			} else {
+				s.Len(v.Errors, 1)
+				for _, want := range c.wantErrors {
+					s.Contains(v.Errors[0].Error(), want)
+				}
+			}
+			if len(c.wantWarnings) > 0 {
+				s.Len(v.Warnings, len(c.wantWarnings))
+				for i, want := range c.wantWarnings {
+					s.Contains(v.Warnings[i], want)
+				}
+			}
+		})
+	}
+}
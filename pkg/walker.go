@@ -0,0 +1,322 @@
+package pkg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/spf13/afero"
+)
+
+// fileWalker finds files and directories that match a glob pattern. It supports `**` like `src/**/*.go`,
+// `exclude` patterns, and could skip files ignored by `.gitignore` files.
+type fileWalker struct {
+	glob             string
+	excludes         []string
+	respectGitIgnore bool
+	// caseInsensitive makes the glob pattern match paths in any case, like `LICENSE*` matches `license.md`, exclude patterns are still case-sensitive.
+	caseInsensitive bool
+}
+
+func newFileWalker(glob string, excludes []string, respectGitIgnore bool) *fileWalker {
+	w := &fileWalker{
+		glob:             glob,
+		respectGitIgnore: respectGitIgnore,
+	}
+	for _, e := range excludes {
+		w.excludes = append(w.excludes, cleanGlob(e))
+	}
+	return w
+}
+
+// Glob returns all matched paths in lexical order, like afero.Glob, matched directories are included.
+func (w *fileWalker) Glob(fs afero.Fs) ([]string, error) {
+	pattern := cleanGlob(w.glob)
+	if !doublestar.ValidatePattern(pattern) {
+		return nil, fmt.Errorf("invalid glob pattern %s", w.glob)
+	}
+	for _, e := range w.excludes {
+		if !doublestar.ValidatePattern(e) {
+			return nil, fmt.Errorf("invalid exclude pattern %s", e)
+		}
+	}
+	base, _ := doublestar.SplitPattern(pattern)
+	if w.caseInsensitive {
+		var err error
+		if base, err = existingPrefix(fs, base); err != nil {
+			return nil, err
+		}
+		pattern = foldAfter(base, pattern)
+	}
+	info, err := lstatIfPossible(fs, filepath.FromSlash(base))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ignores *gitIgnore
+	if w.respectGitIgnore {
+		if ignores, err = loadParentGitIgnores(fs, base); err != nil {
+			return nil, err
+		}
+		if ignores.parentIgnored(base) || (base != pattern && ignores.ignored(base, true)) {
+			return nil, nil
+		}
+	}
+	var matches []string
+	if base == pattern {
+		// no meta character in pattern
+		if !w.skip(base, info.IsDir(), ignores) {
+			matches = append(matches, base)
+		}
+		return matches, nil
+	}
+	if !info.IsDir() {
+		return nil, nil
+	}
+	err = w.walk(fs, base, base, pattern, ignores, &matches)
+	return matches, err
+}
+
+// walk matches paths under dir against pattern, base is the part of the pattern that's matched as it is, even if the walker is case-insensitive.
+func (w *fileWalker) walk(fs afero.Fs, base, dir, pattern string, ignores *gitIgnore, matches *[]string) error {
+	var err error
+	if w.respectGitIgnore {
+		if ignores, err = ignores.load(fs, dir); err != nil {
+			return err
+		}
+	}
+	entries, err := afero.ReadDir(fs, filepath.FromSlash(dir))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		p := path.Join(dir, entry.Name())
+		isDir := entry.IsDir()
+		if w.skip(p, isDir, ignores) {
+			continue
+		}
+		if doublestar.MatchUnvalidated(pattern, w.fold(base, p)) {
+			*matches = append(*matches, p)
+		}
+		if isDir && mightMatchUnder(pattern, w.fold(base, p)) {
+			if err = w.walk(fs, base, p, pattern, ignores, matches); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *fileWalker) fold(base, p string) string {
+	if w.caseInsensitive {
+		return foldAfter(base, p)
+	}
+	return p
+}
+
+// existingPrefix returns the longest prefix of the literal base of a case-insensitive pattern that exists as it is,
+// the first component that doesn't exist might be in another case on disk, so it's looked up by walking its parent.
+func existingPrefix(fs afero.Fs, base string) (string, error) {
+	for {
+		_, err := lstatIfPossible(fs, filepath.FromSlash(base))
+		if err == nil {
+			return base, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := path.Dir(base)
+		if parent == base {
+			return base, nil
+		}
+		base = parent
+	}
+}
+
+// foldAfter lowercases the part of p after base, base is kept as it is.
+func foldAfter(base, p string) string {
+	if base == "." {
+		return strings.ToLower(p)
+	}
+	return p[:len(base)] + strings.ToLower(p[len(base):])
+}
+
+func (w *fileWalker) skip(p string, isDir bool, ignores *gitIgnore) bool {
+	for _, e := range w.excludes {
+		if doublestar.MatchUnvalidated(e, p) {
+			return true
+		}
+	}
+	if !w.respectGitIgnore {
+		return false
+	}
+	if isDir && path.Base(p) == ".git" {
+		return true
+	}
+	return ignores.ignored(p, isDir)
+}
+
+// mightMatchUnder returns false when the pattern could not match any path under the directory, so we can skip walking it.
+func mightMatchUnder(pattern, dir string) bool {
+	if strings.Contains(pattern, "**") {
+		return true
+	}
+	patternSegments, dirSegments := strings.Split(pattern, "/"), strings.Split(dir, "/")
+	if len(patternSegments) <= len(dirSegments) {
+		return false
+	}
+	// a literal segment must be the same, so only the matched directory is walked
+	last := len(dirSegments) - 1
+	segment := patternSegments[last]
+	return strings.ContainsAny(segment, "*?[{\\") || segment == dirSegments[last]
+}
+
+func cleanGlob(glob string) string {
+	return path.Clean(filepath.ToSlash(glob))
+}
+
+func lstatIfPossible(fs afero.Fs, name string) (os.FileInfo, error) {
+	if lfs, ok := fs.(afero.Lstater); ok {
+		info, _, err := lfs.LstatIfPossible(name)
+		return info, err
+	}
+	return fs.Stat(name)
+}
+
+// gitIgnore holds patterns from `.gitignore` files, deeper `.gitignore` files are appended after their parents, so the last matched pattern wins.
+type gitIgnore struct {
+	patterns []gitIgnorePattern
+}
+
+type gitIgnorePattern struct {
+	// dir is the directory where the `.gitignore` file is, patterns are relative to it.
+	dir      string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// loadParentGitIgnores loads `.gitignore` files from the directories above dir, stop at the directory that contains `.git`.
+func loadParentGitIgnores(fs afero.Fs, dir string) (*gitIgnore, error) {
+	var parents []string
+	for d := path.Dir(dir); d != dir; d = path.Dir(d) {
+		parents = append([]string{d}, parents...)
+		if exists, _ := afero.DirExists(fs, filepath.FromSlash(path.Join(d, ".git"))); exists {
+			break
+		}
+		dir = d
+	}
+	ignores := &gitIgnore{}
+	var err error
+	for _, d := range parents {
+		if ignores, err = ignores.load(fs, d); err != nil {
+			return nil, err
+		}
+	}
+	return ignores, nil
+}
+
+// load returns a new gitIgnore with patterns from the `.gitignore` file in dir, it returns itself if there's no such file.
+func (g *gitIgnore) load(fs afero.Fs, dir string) (*gitIgnore, error) {
+	f, err := fs.Open(filepath.FromSlash(path.Join(dir, ".gitignore")))
+	if os.IsNotExist(err) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	var patterns []gitIgnorePattern
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if p, ok := parseGitIgnorePattern(dir, scanner.Text()); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return g, nil
+	}
+	return &gitIgnore{
+		patterns: append(append([]gitIgnorePattern{}, g.patterns...), patterns...),
+	}, nil
+}
+
+func parseGitIgnorePattern(dir, line string) (gitIgnorePattern, bool) {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line, " \t")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return gitIgnorePattern{}, false
+	}
+	p := gitIgnorePattern{dir: dir}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	// a pattern with separator at the beginning or middle is relative to the `.gitignore` file
+	p.anchored = strings.Contains(line, "/")
+	p.pattern = strings.TrimPrefix(line, "/")
+	if p.pattern == "" {
+		return gitIgnorePattern{}, false
+	}
+	return p, true
+}
+
+func (g *gitIgnore) ignored(p string, isDir bool) bool {
+	if g == nil {
+		return false
+	}
+	ignored := false
+	for _, pattern := range g.patterns {
+		if pattern.match(p, isDir) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+// parentIgnored returns true if any parent directory of p is ignored, so p is ignored too.
+func (g *gitIgnore) parentIgnored(p string) bool {
+	for d := path.Dir(p); d != "." && d != "/" && d != p; p, d = d, path.Dir(d) {
+		if g.ignored(d, true) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p gitIgnorePattern) match(name string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	rel := name
+	if p.dir != "." {
+		var ok bool
+		if rel, ok = strings.CutPrefix(name, strings.TrimSuffix(p.dir, "/")+"/"); !ok {
+			return false
+		}
+	}
+	if !p.anchored {
+		return doublestar.MatchUnvalidated(p.pattern, path.Base(rel))
+	}
+	return doublestar.MatchUnvalidated(p.pattern, rel)
+}
@@ -0,0 +1,195 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/suite"
+)
+
+type fileWalkerSuite struct {
+	suite.Suite
+	*testBase
+}
+
+func TestFileWalkerSuite(t *testing.T) {
+	suite.Run(t, new(fileWalkerSuite))
+}
+
+func (s *fileWalkerSuite) SetupTest() {
+	s.testBase = newTestBase()
+	s.dummyFsWithFiles([]string{
+		"/repo/.git/config",
+		"/repo/.gitignore",
+		"/repo/main.go",
+		"/repo/main.log",
+		"/repo/pkg/a.go",
+		"/repo/pkg/a_test.go",
+		"/repo/pkg/.gitignore",
+		"/repo/pkg/gen/b.go",
+		"/repo/pkg/keep.log",
+		"/repo/vendor/lib/c.go",
+		"/repo/build/d.go",
+		"/repo/LICENSE",
+		"/repo/Docs/License.md",
+	}, []string{
+		"",
+		"*.log\nvendor/\n/build\n",
+		"",
+		"",
+		"",
+		"",
+		"gen/\n!keep.log\n",
+		"",
+		"",
+		"",
+		"",
+		"",
+		"",
+	})
+}
+
+func (s *fileWalkerSuite) TearDownTest() {
+	s.teardown()
+}
+
+func (s *fileWalkerSuite) TestGlob() {
+	cases := []struct {
+		desc             string
+		glob             string
+		excludes         []string
+		respectGitIgnore bool
+		want             []string
+	}{
+		{
+			desc: "double_star",
+			glob: "/repo/**/*.go",
+			want: []string{
+				"/repo/build/d.go",
+				"/repo/main.go",
+				"/repo/pkg/a.go",
+				"/repo/pkg/a_test.go",
+				"/repo/pkg/gen/b.go",
+				"/repo/vendor/lib/c.go",
+			},
+		},
+		{
+			desc: "single_star_does_not_cross_directories",
+			glob: "/repo/*/*.go",
+			want: []string{
+				"/repo/build/d.go",
+				"/repo/pkg/a.go",
+				"/repo/pkg/a_test.go",
+			},
+		},
+		{
+			desc:     "exclude",
+			glob:     "/repo/**/*.go",
+			excludes: []string{"/repo/vendor/**", "**/*_test.go"},
+			want: []string{
+				"/repo/build/d.go",
+				"/repo/main.go",
+				"/repo/pkg/a.go",
+				"/repo/pkg/gen/b.go",
+			},
+		},
+		{
+			desc:             "respect_gitignore",
+			glob:             "/repo/**/*.go",
+			respectGitIgnore: true,
+			want: []string{
+				"/repo/main.go",
+				"/repo/pkg/a.go",
+				"/repo/pkg/a_test.go",
+			},
+		},
+		{
+			desc:             "nested_gitignore_negation",
+			glob:             "/repo/**/*.log",
+			respectGitIgnore: true,
+			want: []string{
+				"/repo/pkg/keep.log",
+			},
+		},
+		{
+			desc:             "gitignore_from_parent_directory",
+			glob:             "/repo/pkg/gen/*.go",
+			respectGitIgnore: true,
+			want:             nil,
+		},
+		{
+			desc:             "literal_path_ignored",
+			glob:             "/repo/vendor/lib/c.go",
+			respectGitIgnore: true,
+			want:             nil,
+		},
+		{
+			desc: "literal_path",
+			glob: "/repo/vendor/lib/c.go",
+			want: []string{"/repo/vendor/lib/c.go"},
+		},
+		{
+			desc: "no_match",
+			glob: "/repo/**/*.rs",
+			want: nil,
+		},
+	}
+	for _, c := range cases {
+		s.Run(c.desc, func() {
+			matches, err := newFileWalker(c.glob, c.excludes, c.respectGitIgnore).Glob(s.fs)
+			s.NoError(err)
+			s.Equal(c.want, matches)
+		})
+	}
+}
+
+func (s *fileWalkerSuite) TestGlob_InvalidPattern() {
+	_, err := newFileWalker("/repo/[", nil, false).Glob(s.fs)
+	s.Error(err)
+}
+
+func (s *fileWalkerSuite) TestGlob_CaseInsensitive() {
+	w := newFileWalker("/repo/**/license*", nil, false)
+	w.caseInsensitive = true
+	matches, err := w.Glob(s.fs)
+	s.NoError(err)
+	s.Equal([]string{"/repo/Docs/License.md", "/repo/LICENSE"}, matches)
+
+	w = newFileWalker("/repo/docs/LICENSE.md", nil, false)
+	w.caseInsensitive = true
+	matches, err = w.Glob(s.fs)
+	s.NoError(err)
+	s.Equal([]string{"/repo/Docs/License.md"}, matches)
+}
+
+func (s *fileWalkerSuite) TestGlob_CaseInsensitiveShouldOnlyLookUpDifferingComponent() {
+	fs := &openRecordingFs{Fs: s.fs}
+	w := newFileWalker("/repo/docs/LICENSE.md", nil, false)
+	w.caseInsensitive = true
+	matches, err := w.Glob(fs)
+	s.NoError(err)
+	s.Equal([]string{"/repo/Docs/License.md"}, matches)
+	s.Equal([]string{"/repo", "/repo/Docs"}, fs.opened)
+
+	// the first differing component is looked up in its parent
+	w = newFileWalker("/REPO/docs/*", nil, false)
+	w.caseInsensitive = true
+	fs.opened = nil
+	matches, err = w.Glob(fs)
+	s.NoError(err)
+	s.Equal([]string{"/repo/Docs/License.md"}, matches)
+	s.Equal([]string{"/", "/repo", "/repo/Docs"}, fs.opened)
+}
+
+// openRecordingFs records opened paths, so tests can check which directories are walked.
+type openRecordingFs struct {
+	afero.Fs
+	opened []string
+}
+
+func (fs *openRecordingFs) Open(name string) (afero.File, error) {
+	if info, err := fs.Fs.Stat(name); err == nil && info.IsDir() {
+		fs.opened = append(fs.opened, name)
+	}
+	return fs.Fs.Open(name)
+}